@@ -0,0 +1,22 @@
+package test
+
+import (
+	logger "github.com/alecthomas/log4go"
+	"github.com/gcggcg/k8s-core-components/k8s"
+	"testing"
+)
+
+func TestAggregateStats(t *testing.T) {
+	logger.Info("=================================TestAggregateStats=====================================")
+	for _, by := range []string{k8s.AggregateByNode, k8s.AggregateByLabel, k8s.AggregateByNamespace} {
+		groups, err := k8s.DefaultK8SMgr.AggregateStats(by, "test-label")
+		if err != nil {
+			logger.Error("aggregate stats by %s 命令执行TestAggregateStats失败, error[%s]", by, err)
+			continue
+		}
+		for _, group := range groups {
+			logger.Info("【分组: %s=%s】app数量: %d, 运行: %d, cpu: %d/%d毫核(%v%%), 内存: %d/%dMB(%v%%)", by, group.Key, group.AppCount, group.Running,
+				group.CpuLoad.Used, group.CpuLoad.Total, group.CpuLoad.Ratio, group.MemLoad.Used, group.MemLoad.Total, group.MemLoad.Ratio)
+		}
+	}
+}
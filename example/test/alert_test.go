@@ -0,0 +1,37 @@
+package test
+
+import (
+	logger "github.com/alecthomas/log4go"
+	"github.com/gcggcg/k8s-core-components/k8s"
+	"testing"
+	"time"
+)
+
+type logNotifier struct{}
+
+func (logNotifier) Name() string { return "log" }
+func (logNotifier) Notify(notification k8s.Notification) error {
+	logger.Info("【%s: %s】级别: %s, %s", notification.Type, notification.Status, notification.Severity, notification.Message)
+	return nil
+}
+
+func TestAlertRules(t *testing.T) {
+	logger.Info("=================================TestAlertRules=====================================")
+	k8s.DefaultK8SMgr.SetNotifiers(logNotifier{})
+	err := k8s.DefaultK8SMgr.SetAlertRules(
+		k8s.AlertRule{Name: "cpu-high", Metric: k8s.AlertMetricCpuRatio, Threshold: 80, Duration: 30 * time.Second, Severity: k8s.SeverityWarning},
+		k8s.AlertRule{Name: "restart-often", Metric: k8s.AlertMetricRestarts, Operator: ">=", Threshold: 3, Duration: 10 * time.Minute, Severity: k8s.SeverityCritical},
+	)
+	if err != nil {
+		logger.Error("set alert rules 命令执行TestAlertRules失败, error[%s]", err)
+		return
+	}
+	if _, err = k8s.DefaultK8SMgr.AddSilence(k8s.Silence{Rule: "cpu-high", App: "mysql", Comment: "压测", ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+		logger.Error("add silence 命令执行TestAlertRules失败, error[%s]", err)
+		return
+	}
+	time.Sleep(time.Minute)
+	for _, alert := range k8s.DefaultK8SMgr.ActiveAlerts() {
+		logger.Info("【容器: %s】告警规则: %s, 状态: %s, 当前值: %v, 开始时间: %v", alert.App, alert.Rule, alert.State, alert.Value, alert.Since)
+	}
+}
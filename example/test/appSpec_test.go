@@ -0,0 +1,44 @@
+package test
+
+import (
+	logger "github.com/alecthomas/log4go"
+	"github.com/gcggcg/k8s-core-components/k8s"
+	"os"
+	"testing"
+)
+
+func TestAppSpecApply(t *testing.T) {
+	logger.Info("=================================TestAppSpecApply=================================")
+	data, err := os.ReadFile("../spec/mysql.yaml")
+	if err != nil {
+		logger.Error("读取app描述文件失败, error[%s]", err)
+		return
+	}
+	results, err := k8s.DefaultK8SMgr.AppSpecApply(data, true)
+	if err != nil {
+		logger.Error("app spec apply 命令执行TestAppSpecApply失败, error[%s]", err)
+		return
+	}
+	for _, result := range results {
+		if result.Error != nil {
+			logger.Error("【容器: %s】app spec %s 命令执行TestAppSpecApply失败, error[%s]", result.Name, result.Action, result.Error)
+		} else {
+			logger.Info("【容器: %s】app spec %s 命令执行TestAppSpecApply成功", result.Name, result.Action)
+		}
+	}
+}
+
+func TestAppSpecExport(t *testing.T) {
+	logger.Info("=================================TestAppSpecExport=================================")
+	spec, err := k8s.DefaultK8SMgr.AppSpecExport("test-create-mysql")
+	if err != nil {
+		logger.Error("【容器: test-create-mysql】export app spec 命令执行TestAppSpecExport失败, error[%s]", err)
+		return
+	}
+	data, err := k8s.MarshalAppSpecs(spec)
+	if err != nil {
+		logger.Error("【容器: test-create-mysql】export app spec 命令执行TestAppSpecExport失败, error[%s]", err)
+		return
+	}
+	logger.Info("【容器: test-create-mysql】export app spec 命令执行TestAppSpecExport成功:\n%s", data)
+}
@@ -0,0 +1,32 @@
+package test
+
+import (
+	logger "github.com/alecthomas/log4go"
+	"github.com/gcggcg/k8s-core-components/k8s"
+	"os"
+	"testing"
+)
+
+func TestConvertCompose(t *testing.T) {
+	logger.Info("=================================TestConvertCompose=================================")
+	data, err := os.ReadFile("../compose/docker-compose.yml")
+	if err != nil {
+		logger.Error("读取docker-compose文件失败, error[%s]", err)
+		return
+	}
+	infos, report, err := k8s.ConvertCompose(data, "127.0.0.1")
+	if err != nil {
+		logger.Error("convert compose 命令执行TestConvertCompose失败, error[%s]", err)
+		return
+	}
+	if len(report.Issues) > 0 {
+		logger.Warn("docker-compose 不支持的配置:\n%s", report)
+	}
+	for i := range infos {
+		if err = k8s.DefaultK8SMgr.StatefulSetCreate(&infos[i], true); err != nil {
+			logger.Error("【容器: %s】create container 命令执行TestConvertCompose失败, error[%s]", infos[i].Name, err)
+		} else {
+			logger.Info("【容器: %s】create container 命令执行TestConvertCompose成功", infos[i].Name)
+		}
+	}
+}
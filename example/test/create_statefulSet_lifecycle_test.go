@@ -0,0 +1,33 @@
+package test
+
+import (
+	logger "github.com/alecthomas/log4go"
+	"github.com/gcggcg/k8s-core-components/k8s"
+	"testing"
+)
+
+func TestCreatePodLifecycle(t *testing.T) {
+	logger.Info("=================================TestCreatePodLifecycle=================================")
+	gracePeriod := int64(60)
+	err := k8s.DefaultK8SMgr.StatefulSetCreate(&k8s.CreateReqInfo{
+		Name:       "test-create-redis",
+		NodeName:   "127.0.0.1",
+		Image:      "redis:6.2",
+		Command:    []string{"redis-server"},
+		Args:       []string{"--appendonly", "yes"},
+		WorkingDir: "/data",
+		PostStart:  &k8s.HookInfo{Command: []string{"/bin/sh", "-c", "echo started > /tmp/started"}},
+		PreStop:    &k8s.HookInfo{Command: []string{"redis-cli", "shutdown", "save"}},
+		Port: []k8s.PortInfo{
+			{InnerPort: 6379, OuterPort: 63791, Protocol: "TCP"},
+		},
+		TerminationGracePeriod: &gracePeriod,
+		Restart:                k8s.RESTART_Always,
+	}, true)
+	if err != nil {
+		logger.Error("【容器: test-create-redis】create container 命令执行TestCreatePodLifecycle失败, error[%s]", err)
+		return
+	} else {
+		logger.Info("【容器: test-create-redis】create container 命令执行TestCreatePodLifecycle成功")
+	}
+}
@@ -0,0 +1,36 @@
+package test
+
+import (
+	logger "github.com/alecthomas/log4go"
+	"github.com/gcggcg/k8s-core-components/k8s"
+	"testing"
+)
+
+func TestCreatePodScheduling(t *testing.T) {
+	logger.Info("=================================TestCreatePodScheduling=================================")
+	err := k8s.DefaultK8SMgr.StatefulSetCreate(&k8s.CreateReqInfo{
+		Name:  "test-create-etcd",
+		Image: "quay.io/coreos/etcd:v3.5.9",
+		Tolerations: []k8s.TolerationInfo{
+			{Key: "node-role.kubernetes.io/control-plane", Operator: "Exists", Effect: "NoSchedule"},
+		},
+		NodeAffinity: []k8s.NodeAffinityInfo{
+			{Key: "disktype", Operator: "In", Values: []string{"ssd"}, Weight: 80},
+		},
+		PriorityClassName: "high-priority", // 需要提前创建 PriorityClass, system- 开头的优先级仅允许在 kube-system 空间使用
+		DNS: &k8s.DNSInfo{
+			Policy:  "ClusterFirst",
+			Options: []string{"ndots:2"},
+		},
+		HostAliases: []k8s.HostAliasInfo{
+			{IP: "127.0.0.1", Hostnames: []string{"etcd.local"}},
+		},
+		Restart: k8s.RESTART_Always,
+	}, true)
+	if err != nil {
+		logger.Error("【容器: test-create-etcd】create container 命令执行TestCreatePodScheduling失败, error[%s]", err)
+		return
+	} else {
+		logger.Info("【容器: test-create-etcd】create container 命令执行TestCreatePodScheduling成功")
+	}
+}
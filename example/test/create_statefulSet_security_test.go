@@ -0,0 +1,37 @@
+package test
+
+import (
+	logger "github.com/alecthomas/log4go"
+	"github.com/gcggcg/k8s-core-components/k8s"
+	"testing"
+)
+
+func TestCreatePodSecurity(t *testing.T) {
+	logger.Info("=================================TestCreatePodSecurity=================================")
+	uid, gid := int64(1000), int64(1000)
+	err := k8s.DefaultK8SMgr.StatefulSetCreate(&k8s.CreateReqInfo{
+		Name:        "test-create-keepalived",
+		NodeName:    "127.0.0.1",
+		Image:       "osixia/keepalived:2.0.20",
+		HostNetwork: true,
+		Security: k8s.SecurityInfo{
+			CapAdd:         []string{"NET_ADMIN", "NET_BROADCAST"},
+			CapDrop:        []string{"ALL"},
+			RunAsUser:      &uid,
+			RunAsGroup:     &gid,
+			FsGroup:        &gid,
+			ReadOnlyRootFs: true,
+			SeccompProfile: k8s.SeccompRuntimeDefault,
+			Device: []k8s.DeviceInfo{
+				{HostPath: "/dev/net/tun", ContainerPath: "/dev/net/tun"},
+			},
+		},
+		Restart: k8s.RESTART_Always,
+	}, true)
+	if err != nil {
+		logger.Error("【容器: test-create-keepalived】create container 命令执行TestCreatePodSecurity失败, error[%s]", err)
+		return
+	} else {
+		logger.Info("【容器: test-create-keepalived】create container 命令执行TestCreatePodSecurity成功")
+	}
+}
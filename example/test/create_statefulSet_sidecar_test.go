@@ -0,0 +1,46 @@
+package test
+
+import (
+	logger "github.com/alecthomas/log4go"
+	"github.com/gcggcg/k8s-core-components/k8s"
+	"testing"
+)
+
+func TestCreatePodSidecar(t *testing.T) {
+	logger.Info("=================================TestCreatePodSidecar=================================")
+	err := k8s.DefaultK8SMgr.StatefulSetCreate(&k8s.CreateReqInfo{
+		Name:     "test-create-nginx",
+		NodeName: "127.0.0.1",
+		Image:    "nginx:1.23",
+		Port: []k8s.PortInfo{
+			{InnerPort: 80, OuterPort: 8080, Protocol: "TCP"},
+		},
+		Volume: []k8s.VolumeInfo{
+			{InnerPath: "/var/log/nginx", OuterPath: "/opt/data/nginx/log"},
+		},
+		InitContainers: []k8s.ContainerSpecInfo{
+			{Name: "init-conf", Image: "busybox:1.36", Command: []string{"sh", "-c", "mkdir -p /conf && echo ok > /conf/ready"}},
+		},
+		Sidecars: []k8s.ContainerSpecInfo{
+			{
+				Name:   "log-agent",
+				Image:  "fluent/fluent-bit:2.1",
+				Volume: []k8s.VolumeInfo{{InnerPath: "/var/log/nginx", OuterPath: "/opt/data/nginx/log"}},
+				Port:   []k8s.PortInfo{{InnerPort: 2020, OuterPort: 2020, Protocol: "TCP"}},
+			},
+		},
+		Restart: k8s.RESTART_Always,
+	}, true)
+	if err != nil {
+		logger.Error("【容器: test-create-nginx】create container 命令执行TestCreatePodSidecar失败, error[%s]", err)
+		return
+	}
+	info, err := k8s.DefaultK8SMgr.ContainerInfo("test-create-nginx", appNamespace)
+	if err != nil {
+		logger.Error("【容器: test-create-nginx】get container info 命令执行TestCreatePodSidecar失败, error[%s]", err)
+		return
+	}
+	for _, container := range info.Containers {
+		logger.Info("【容器: test-create-nginx】容器: %s, 状态: %+v", container.Name, container)
+	}
+}
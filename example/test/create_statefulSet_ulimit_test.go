@@ -0,0 +1,35 @@
+package test
+
+import (
+	logger "github.com/alecthomas/log4go"
+	"github.com/gcggcg/k8s-core-components/k8s"
+	"testing"
+)
+
+func TestCreatePodUlimitMacAddress(t *testing.T) {
+	logger.Info("=================================TestCreatePodUlimitMacAddress=================================")
+	err := k8s.DefaultK8SMgr.SetClusterOptions(k8s.ClusterOptions{MacAddressAnnotation: "ovn.kubernetes.io/mac_address", EnableUlimit: true})
+	if err != nil {
+		logger.Error("set cluster options 命令执行TestCreatePodUlimitMacAddress失败, error[%s]", err)
+		return
+	}
+	err = k8s.DefaultK8SMgr.StatefulSetCreate(&k8s.CreateReqInfo{
+		Name:     "test-create-nginx",
+		NodeName: "127.0.0.1",
+		Image:    "nginx:1.23",
+		Env: []k8s.EnvInfo{
+			{Key: "ULIMIT", Value: "nofile=65535:65535,nproc=4096"},
+			{Key: "MACADDRESS", Value: "02:42:ac:11:00:02"},
+		},
+		Port: []k8s.PortInfo{
+			{InnerPort: 80, OuterPort: 8080, Protocol: "TCP"},
+		},
+		Restart: k8s.RESTART_Always,
+	}, true)
+	if err != nil {
+		logger.Error("【容器: test-create-nginx】create container 命令执行TestCreatePodUlimitMacAddress失败, error[%s]", err)
+		return
+	} else {
+		logger.Info("【容器: test-create-nginx】create container 命令执行TestCreatePodUlimitMacAddress成功")
+	}
+}
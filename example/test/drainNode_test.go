@@ -0,0 +1,34 @@
+package test
+
+import (
+	logger "github.com/alecthomas/log4go"
+	"github.com/gcggcg/k8s-core-components/k8s"
+	"testing"
+)
+
+func TestCordonNode(t *testing.T) {
+	logger.Info("=================================TestCordonNode=================================")
+	err := k8s.DefaultK8SMgr.NodeCordon("127.0.0.1", true)
+	if err != nil {
+		logger.Error("【节点: 127.0.0.1】cordon node 命令执行TestCordonNode失败, error[%s]", err)
+		return
+	} else {
+		logger.Info("【节点: 127.0.0.1】cordon node 命令执行TestCordonNode成功")
+	}
+}
+
+func TestDrainNode(t *testing.T) {
+	logger.Info("=================================TestDrainNode=================================")
+	results, err := k8s.DefaultK8SMgr.NodeDrain("127.0.0.1", k8s.DrainOptions{
+		AutoSelect: true,
+		Progress: func(p k8s.DrainResult) {
+			logger.Info("【容器: %s】drain 进度: %+v", p.Name, p)
+		},
+	}, true)
+	if err != nil {
+		logger.Error("【节点: 127.0.0.1】drain node 命令执行TestDrainNode失败, error[%s]", err)
+		return
+	} else {
+		logger.Info("【节点: 127.0.0.1】drain node 命令执行TestDrainNode成功, 结果: %+v", results)
+	}
+}
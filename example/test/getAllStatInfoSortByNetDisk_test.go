@@ -0,0 +1,28 @@
+package test
+
+import (
+	logger "github.com/alecthomas/log4go"
+	"github.com/gcggcg/k8s-core-components/k8s"
+	"testing"
+	"time"
+)
+
+func TestGetAllStatInfoOfSortByNet(t *testing.T) {
+	logger.Info("=================================TestGetAllStatInfoOfSortByNet=====================================")
+	desc := true
+	// 网络速率需要两次采集
+	time.Sleep(10 * time.Second)
+	list := k8s.DefaultK8SMgr.GetAllStatInfoOfSortByNet(desc, appNamespace)
+	for _, info := range list {
+		logger.Info("【域名空间: %s】get all container stat info 总数据: %d, by desc:%v, 接收: %vB/s, 发送: %vB/s", appNamespace, len(list), desc, info.NetLoad.RxRate, info.NetLoad.TxRate)
+	}
+}
+
+func TestGetAllStatInfoOfSortByDisk(t *testing.T) {
+	logger.Info("=================================TestGetAllStatInfoOfSortByDisk=====================================")
+	desc := true
+	list := k8s.DefaultK8SMgr.GetAllStatInfoOfSortByDisk(desc, appNamespace)
+	for _, info := range list {
+		logger.Info("【域名空间: %s】get all container stat info 总数据: %d, by desc:%v, 可写层: %vMB, 卷: %vMB, %+v", appNamespace, len(list), desc, info.DiskLoad.RootfsUsed, info.DiskLoad.VolumeUsed, info.DiskLoad.Volumes)
+	}
+}
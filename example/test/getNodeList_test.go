@@ -0,0 +1,30 @@
+package test
+
+import (
+	logger "github.com/alecthomas/log4go"
+	"github.com/gcggcg/k8s-core-components/k8s"
+	"testing"
+)
+
+func TestListNodes(t *testing.T) {
+	logger.Info("=================================TestListNodes=================================")
+	nodes, err := k8s.DefaultK8SMgr.ListNodes()
+	if err != nil {
+		logger.Error("get all node info 命令执行TestListNodes失败, error[%s]", err)
+		return
+	}
+	for _, node := range nodes {
+		logger.Info("【节点: %s】get node info: %+v", node.Name, node)
+	}
+}
+
+func TestGetNode(t *testing.T) {
+	logger.Info("=================================TestGetNode=================================")
+	node, err := k8s.DefaultK8SMgr.GetNode("127.0.0.1")
+	if err != nil {
+		logger.Error("【节点: 127.0.0.1】get node info 命令执行TestGetNode失败, error[%s]", err)
+		return
+	} else {
+		logger.Info("【节点: 127.0.0.1】get node info: %+v", node)
+	}
+}
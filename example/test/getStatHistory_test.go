@@ -0,0 +1,24 @@
+package test
+
+import (
+	logger "github.com/alecthomas/log4go"
+	"github.com/gcggcg/k8s-core-components/k8s"
+	"testing"
+	"time"
+)
+
+func TestGetStatHistory(t *testing.T) {
+	logger.Info("=================================TestGetStatHistory====================================")
+	// 等待采集若干个周期
+	time.Sleep(time.Minute)
+	now := time.Now()
+	history, err := k8s.DefaultK8SMgr.GetStatHistory("etcd-yun-v1", "plate-app", now.Add(-time.Hour), now, time.Minute)
+	if err != nil {
+		logger.Error("【容器: etcd-yun-v1】get container stat history 命令执行TestGetStatHistory失败, error[%s]", err)
+		return
+	}
+	for i := range history.Cpu {
+		logger.Info("【容器: etcd-yun-v1】%s cpu: %v毫核(%v%%), 内存: %vMB(%v%%)", history.Cpu[i].At.Format(time.RFC3339), history.Cpu[i].Used, history.Cpu[i].Ratio, history.Mem[i].Used, history.Mem[i].Ratio)
+	}
+	logger.Info("【容器: etcd-yun-v1】cpu统计: %+v, 内存统计: %+v", history.CpuSummary, history.MemSummary)
+}
@@ -0,0 +1,32 @@
+package test
+
+import (
+	"errors"
+	logger "github.com/alecthomas/log4go"
+	"github.com/gcggcg/k8s-core-components/k8s"
+	"testing"
+)
+
+func TestCreatePodHostPortConflict(t *testing.T) {
+	logger.Info("=================================TestCreatePodHostPortConflict=================================")
+	err := k8s.DefaultK8SMgr.StatefulSetCreate(&k8s.CreateReqInfo{
+		Name:     "test-create-mysql2",
+		NodeName: "127.0.0.1",
+		Image:    "mysql:5.7.18",
+		Env: []k8s.EnvInfo{
+			{Key: "MYSQL_ROOT_PASSWORD", Value: "123root"},
+		},
+		Port: []k8s.PortInfo{
+			{InnerPort: 3306, OuterPort: 3306, Protocol: "TCP"}, // 与host模式的test-create-mysql冲突
+		},
+		Restart: k8s.RESTART_Always,
+	}, true)
+	var conflict *k8s.HostPortConflictError
+	if errors.As(err, &conflict) {
+		logger.Info("【容器: test-create-mysql2】主机端口冲突, 占用端口的容器: %s, 端口: %d/%s", conflict.Owner, conflict.Port, conflict.Protocol)
+	} else if err != nil {
+		logger.Error("【容器: test-create-mysql2】create container 命令执行TestCreatePodHostPortConflict失败, error[%s]", err)
+	} else {
+		logger.Info("【容器: test-create-mysql2】主机端口未冲突")
+	}
+}
@@ -0,0 +1,24 @@
+package test
+
+import (
+	logger "github.com/alecthomas/log4go"
+	"github.com/gcggcg/k8s-core-components/k8s"
+	"testing"
+	"time"
+)
+
+func TestImagePrePull(t *testing.T) {
+	logger.Info("=================================TestImagePrePull=================================")
+	results, err := k8s.DefaultK8SMgr.ImagePrePull([]string{"nginx:1.25"}, k8s.PrePullOptions{
+		Nodes:   []string{"127.0.0.1"},
+		Timeout: 5 * time.Minute,
+		Progress: func(p k8s.PrePullResult) {
+			logger.Info("【节点: %s】镜像: %s 预拉取完成: %v, 耗时: %v, error: %s", p.Node, p.Image, p.Done, p.Duration, p.Error)
+		},
+	})
+	if err != nil {
+		logger.Error("【镜像: nginx:1.25】image pre-pull 命令执行TestImagePrePull失败, error[%s]", err)
+		return
+	}
+	logger.Info("【镜像: nginx:1.25】image pre-pull 命令执行TestImagePrePull成功, 结果: %+v", results)
+}
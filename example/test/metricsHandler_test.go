@@ -0,0 +1,24 @@
+package test
+
+import (
+	logger "github.com/alecthomas/log4go"
+	"github.com/gcggcg/k8s-core-components/k8s"
+	"io"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	logger.Info("=================================TestMetricsHandler=================================")
+	// 实际使用: http.Handle("/metrics", k8s.DefaultK8SMgr.MetricsHandler())
+	server := httptest.NewServer(k8s.DefaultK8SMgr.MetricsHandler())
+	defer server.Close()
+	resp, err := server.Client().Get(server.URL + "/metrics")
+	if err != nil {
+		logger.Error("get metrics 命令执行TestMetricsHandler失败, error[%s]", err)
+		return
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	logger.Info("get metrics 命令执行TestMetricsHandler成功:\n%s", body)
+}
@@ -0,0 +1,35 @@
+package test
+
+import (
+	"errors"
+	logger "github.com/alecthomas/log4go"
+	"github.com/gcggcg/k8s-core-components/k8s"
+	"testing"
+	"time"
+)
+
+func TestMonitorControl(t *testing.T) {
+	logger.Info("=================================TestMonitorControl=====================================")
+	err := k8s.DefaultK8SMgr.SetMonitorOptions(appNamespace, k8s.MonitorOptions{Interval: 10 * time.Second, MaxFailures: 5, MaxBackoff: 2 * time.Minute})
+	if err != nil {
+		logger.Error("set monitor options 命令执行TestMonitorControl失败, error[%s]", err)
+		return
+	}
+	logger.Info("【空间: %s】采集配置: %+v", appNamespace, k8s.DefaultK8SMgr.GetMonitorOptions(appNamespace))
+	name := "test-create-mysql"
+	k8s.DefaultK8SMgr.MonitorPause(name, appNamespace)
+	logger.Info("【容器: %s】采集状态: %s", name, k8s.DefaultK8SMgr.MonitorStatus(name, appNamespace))
+	time.Sleep(30 * time.Second)
+	k8s.DefaultK8SMgr.MonitorStop(name, appNamespace)
+	if _, err = k8s.DefaultK8SMgr.GetCacheStatInfo(name, false); !errors.Is(err, k8s.ErrMonitorStopped) {
+		logger.Error("【容器: %s】停止采集后仍然返回资源信息, error[%v]", name, err)
+	}
+	k8s.DefaultK8SMgr.MonitorStart(name, appNamespace)
+	time.Sleep(30 * time.Second)
+	stat, err := k8s.DefaultK8SMgr.GetCacheStatInfo(name, false)
+	if err != nil {
+		logger.Error("get cache stat info 命令执行TestMonitorControl失败, error[%s]", err)
+		return
+	}
+	logger.Info("【容器: %s】采集状态: %s, 资源信息: %+v", name, k8s.DefaultK8SMgr.MonitorStatus(name, appNamespace), stat)
+}
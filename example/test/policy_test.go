@@ -0,0 +1,77 @@
+package test
+
+import (
+	"errors"
+	logger "github.com/alecthomas/log4go"
+	"github.com/gcggcg/k8s-core-components/k8s"
+	"testing"
+)
+
+func TestCreatePodPolicy(t *testing.T) {
+	logger.Info("=================================TestCreatePodPolicy=================================")
+	err := k8s.DefaultK8SMgr.SetPolicies(&k8s.RulePolicy{
+		AllowedRegistries: []string{"harbor.example.com"},
+		AllowedHostPaths:  []string{"/opt/data"},
+		Privileged:        &k8s.Permit{Labels: []k8s.LabelInfo{{Key: "privileged", Value: "allow"}}},
+		HostNetwork:       &k8s.Permit{Apps: []string{"test-create-mysql"}},
+		MaxCpu:            "4",
+		MaxMemory:         "8Gi",
+	})
+	if err != nil {
+		logger.Error("set policies 命令执行TestCreatePodPolicy失败, error[%s]", err)
+		return
+	}
+	defer k8s.DefaultK8SMgr.SetPolicies()
+	err = k8s.DefaultK8SMgr.StatefulSetCreate(&k8s.CreateReqInfo{
+		Name:        "test-create-policy",
+		NodeName:    "127.0.0.1",
+		Image:       "mysql:5.7.18",
+		HostNetwork: true,
+		Env: []k8s.EnvInfo{
+			{Key: "PRIVILEGED", Value: "true"},
+		},
+		Volume: []k8s.VolumeInfo{
+			{InnerPath: "/host", OuterPath: "/"},
+		},
+		Security: k8s.SecurityInfo{CapAdd: []string{"SYS_ADMIN"}},
+		Resource: k8s.ResourceReqInfo{CpuLimit: "8", MemLimit: "4Gi"},
+		Restart:  k8s.RESTART_Always,
+	}, true)
+	var denied *k8s.PolicyDeniedError
+	if errors.As(err, &denied) {
+		for _, denial := range denied.Denials {
+			logger.Info("【容器: test-create-policy】策略拒绝: %+v", denial)
+		}
+	} else {
+		logger.Error("【容器: test-create-policy】create container 命令执行TestCreatePodPolicy应该被策略拒绝, error[%v]", err)
+	}
+}
+
+func TestCreatePodPolicySidecarResource(t *testing.T) {
+	logger.Info("=================================TestCreatePodPolicySidecarResource=================================")
+	err := k8s.DefaultK8SMgr.SetPolicies(&k8s.RulePolicy{MaxCpu: "4", MaxMemory: "8Gi"})
+	if err != nil {
+		logger.Error("set policies 命令执行TestCreatePodPolicySidecarResource失败, error[%s]", err)
+		return
+	}
+	defer k8s.DefaultK8SMgr.SetPolicies()
+	// 主容器未超过限制, 加上边车容器后pod合计超过限制
+	err = k8s.DefaultK8SMgr.StatefulSetCreate(&k8s.CreateReqInfo{
+		Name:     "test-create-policy-sidecar",
+		NodeName: "127.0.0.1",
+		Image:    "mysql:5.7.18",
+		Resource: k8s.ResourceReqInfo{CpuLimit: "2", MemLimit: "4Gi"},
+		Sidecars: []k8s.ContainerSpecInfo{
+			{Name: "log-agent", Image: "busybox:1.36", Resource: k8s.ResourceReqInfo{CpuLimit: "3", MemLimit: "6Gi"}},
+		},
+		Restart: k8s.RESTART_Always,
+	}, true)
+	var denied *k8s.PolicyDeniedError
+	if errors.As(err, &denied) {
+		for _, denial := range denied.Denials {
+			logger.Info("【容器: test-create-policy-sidecar】策略拒绝: %+v", denial)
+		}
+	} else {
+		logger.Error("【容器: test-create-policy-sidecar】create container 命令执行TestCreatePodPolicySidecarResource应该被策略拒绝, error[%v]", err)
+	}
+}
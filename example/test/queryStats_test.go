@@ -0,0 +1,28 @@
+package test
+
+import (
+	logger "github.com/alecthomas/log4go"
+	"github.com/gcggcg/k8s-core-components/k8s"
+	"testing"
+)
+
+func TestQueryStats(t *testing.T) {
+	logger.Info("=================================TestQueryStats=====================================")
+	result, err := k8s.DefaultK8SMgr.QueryStats(k8s.StatQuery{
+		Namespace: appNamespace,
+		SortBy:    k8s.StatSortMemRatio,
+		Desc:      true,
+		Labels:    map[string]string{"test-label": "TestCreatePod"},
+		Status:    k8s.RunningStatus,
+		Top:       10,
+		Offset:    0,
+		Limit:     5,
+	})
+	if err != nil {
+		logger.Error("【域名空间: %s】query stats 命令执行TestQueryStats失败, error[%s]", appNamespace, err)
+		return
+	}
+	for _, item := range result.Items {
+		logger.Info("【域名空间: %s】query stats 总数据: %d, 容器: %s, 节点: %s, 内存: %v%%, 重启次数: %d", appNamespace, result.Total, item.Stat.Name, item.Info.NodeName, item.Stat.MemLoad.Ratio, item.Info.ReStartCount)
+	}
+}
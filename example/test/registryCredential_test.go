@@ -0,0 +1,35 @@
+package test
+
+import (
+	logger "github.com/alecthomas/log4go"
+	"github.com/gcggcg/k8s-core-components/k8s"
+	"testing"
+)
+
+func TestRegistryCredentialApply(t *testing.T) {
+	logger.Info("=================================TestRegistryCredentialApply=================================")
+	err := k8s.DefaultK8SMgr.RegistryCredentialApply(&k8s.RegistryCredential{
+		Name:     "harbor-secret",
+		Server:   "harbor.example.com",
+		Username: "admin",
+		Password: "Harbor12345",
+	}, true)
+	if err != nil {
+		logger.Error("【镜像仓库: harbor.example.com】apply registry credential 命令执行TestRegistryCredentialApply失败, error[%s]", err)
+		return
+	}
+	err = k8s.DefaultK8SMgr.StatefulSetCreate(&k8s.CreateReqInfo{
+		Name:             "test-create-private",
+		NodeName:         "127.0.0.1",
+		Image:            "harbor.example.com/library/app:1.0.0",
+		ImagePullSecrets: []string{"harbor-secret"},
+		ImagePullPolicy:  "IfNotPresent",
+		Restart:          k8s.RESTART_Always,
+	}, true)
+	if err != nil {
+		logger.Error("【容器: test-create-private】create container 命令执行TestRegistryCredentialApply失败, error[%s]", err)
+		return
+	} else {
+		logger.Info("【容器: test-create-private】create container 命令执行TestRegistryCredentialApply成功")
+	}
+}
@@ -0,0 +1,28 @@
+package test
+
+import (
+	logger "github.com/alecthomas/log4go"
+	"github.com/gcggcg/k8s-core-components/k8s"
+	"testing"
+)
+
+func TestCreatePodAuto(t *testing.T) {
+	logger.Info("=================================TestCreatePodAuto=================================")
+	decision, err := k8s.DefaultK8SMgr.StatefulSetCreateAuto(&k8s.CreateReqInfo{
+		Name:  "test-create-redis",
+		Image: "redis:6.2",
+		NodeLabel: []k8s.LabelInfo{
+			{Key: "kubernetes.io/os", Value: "linux"},
+		},
+		Port: []k8s.PortInfo{
+			{InnerPort: 6379, OuterPort: 63791, Protocol: "TCP"},
+		},
+		Restart: k8s.RESTART_Always,
+	}, true)
+	if err != nil {
+		logger.Error("【容器: test-create-redis】create container 命令执行TestCreatePodAuto失败, 调度结果: %+v, error[%s]", decision, err)
+		return
+	} else {
+		logger.Info("【容器: test-create-redis】create container 命令执行TestCreatePodAuto成功, 目标节点: %s, 依据: %s", decision.NodeName, decision.Reason)
+	}
+}
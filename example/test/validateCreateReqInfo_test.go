@@ -0,0 +1,58 @@
+package test
+
+import (
+	logger "github.com/alecthomas/log4go"
+	"github.com/gcggcg/k8s-core-components/k8s"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"testing"
+)
+
+func TestValidateCreateReqInfo(t *testing.T) {
+	logger.Info("=================================TestValidateCreateReqInfo=================================")
+	info := &k8s.CreateReqInfo{
+		Name:     "Test_Create_MySQL",
+		NodeName: "127.0.0.1",
+		Image:    "MySQL:5.7.18",
+		Env: []k8s.EnvInfo{
+			{Key: "MYSQL ROOT PASSWORD", Value: "123root"},
+		},
+		Port: []k8s.PortInfo{
+			{InnerPort: 3306, OuterPort: 33061, Protocol: "TCP"},
+			{InnerPort: 3306, OuterPort: 33061, Protocol: "TCP"},
+			{InnerPort: 0, OuterPort: 33062, Protocol: "HTTP"},
+		},
+		Volume: []k8s.VolumeInfo{
+			{InnerPath: "var/lib/mysql", OuterPath: "/opt/data/../mysql"},
+		},
+		Label: []k8s.LabelInfo{
+			{Key: "app", Value: "mysql"},
+		},
+		Restart: k8s.RESTART_Never,
+	}
+	err := info.Validate()
+	if err == nil {
+		t.Fatal("【容器: Test_Create_MySQL】参数校验应该失败")
+	}
+	if agg, ok := err.(utilerrors.Aggregate); ok {
+		for _, e := range agg.Errors() {
+			logger.Info("【容器: Test_Create_MySQL】参数校验错误: %s", e)
+		}
+	}
+}
+
+func TestValidateUlimitWithPostStart(t *testing.T) {
+	logger.Info("=================================TestValidateUlimitWithPostStart=================================")
+	info := &k8s.CreateReqInfo{
+		Name:      "test-create-ulimit-hook",
+		NodeName:  "127.0.0.1",
+		Image:     "mysql:5.7.18",
+		Env:       []k8s.EnvInfo{{Key: "ULIMIT", Value: "nofile=65535:65535"}},
+		PostStart: &k8s.HookInfo{Command: []string{"touch", "/tmp/started"}},
+		Restart:   k8s.RESTART_Always,
+	}
+	if err := info.Validate(); err == nil {
+		t.Fatal("【容器: test-create-ulimit-hook】ULIMIT 与 PostStart 同时使用时参数校验应该失败")
+	} else {
+		logger.Info("【容器: test-create-ulimit-hook】参数校验错误: %s", err)
+	}
+}
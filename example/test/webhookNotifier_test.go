@@ -0,0 +1,80 @@
+package test
+
+import (
+	"crypto/hmac"
+	logger "github.com/alecthomas/log4go"
+	"github.com/gcggcg/k8s-core-components/k8s"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	logger.Info("=================================TestWebhookNotifier=====================================")
+	const secret = "test-secret"
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		// 第一次请求返回503, 验证重试
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if !hmac.Equal([]byte(r.Header.Get(k8s.WebhookSignatureHeader)), []byte(k8s.SignWebhookBody(secret, body))) {
+			t.Errorf("webhook signature mismatch")
+		}
+		logger.Info("webhook收到通知: %s", body)
+	}))
+	defer server.Close()
+
+	notifier, err := k8s.NewWebhookNotifier("ops-chat", k8s.WebhookOptions{
+		URL:      server.URL,
+		Secret:   secret,
+		Template: `{"msgtype": "text", "text": {"content": {{json .Message}}}, "status": {{json .Status}}}`,
+		Backoff:  100 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("new webhook notifier 命令执行TestWebhookNotifier失败, error[%s]", err)
+	}
+	if err = notifier.Notify(k8s.Notification{Type: k8s.NotificationEvent, Status: k8s.EventCrashed, App: "mysql", Message: "【容器: mysql】异常重启"}); err != nil {
+		t.Fatalf("notify 命令执行TestWebhookNotifier失败, error[%s]", err)
+	}
+	// 关闭时不再重试, 等待重试发送成功后再关闭
+	for i := 0; i < 20 && notifier.Stats().Sent == 0; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+	notifier.Close()
+	stats := notifier.Stats()
+	logger.Info("webhook发送统计: 成功: %d, 失败: %d, 丢弃: %d, 请求次数: %d", stats.Sent, stats.Failed, stats.Dropped, requests)
+	if stats.Sent != 1 || requests != 2 {
+		t.Errorf("unexpected webhook stats: %+v, requests: %d", stats, requests)
+	}
+}
+
+func TestWebhookNotifierNoRetry(t *testing.T) {
+	logger.Info("=================================TestWebhookNotifierNoRetry=====================================")
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// MaxRetries 为0时只发送一次
+	maxRetries := 0
+	notifier, err := k8s.NewWebhookNotifier("ops-chat", k8s.WebhookOptions{URL: server.URL, MaxRetries: &maxRetries})
+	if err != nil {
+		t.Fatalf("new webhook notifier 命令执行TestWebhookNotifierNoRetry失败, error[%s]", err)
+	}
+	if err = notifier.Notify(k8s.Notification{Type: k8s.NotificationEvent, Status: k8s.EventCrashed, App: "mysql", Message: "【容器: mysql】异常重启"}); err != nil {
+		t.Fatalf("notify 命令执行TestWebhookNotifierNoRetry失败, error[%s]", err)
+	}
+	notifier.Close()
+	stats := notifier.Stats()
+	if stats.Failed != 1 || requests != 1 {
+		t.Errorf("unexpected webhook stats: %+v, requests: %d", stats, requests)
+	}
+}
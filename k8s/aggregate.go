@@ -0,0 +1,117 @@
+package k8s
+
+import (
+	"fmt"
+	logger "github.com/alecthomas/log4go"
+	"sort"
+)
+
+/**
+ *    Description: app资源占用的汇总: 按节点, 标签或者空间分组
+ *    Date: 2026/10/19
+ */
+
+const (
+	AggregateByNode      = "node"
+	AggregateByLabel     = "label"
+	AggregateByNamespace = "namespace"
+)
+
+type (
+	// StatGroup 分组的资源占用汇总, Used 为组内app之和, Total 为组内app所在节点(去重)的容量之和, cpu单位: 毫核, 内存单位: MB
+	StatGroup struct {
+		Key      string // 节点地址, 标签的值(app没有该标签时为空)或者空间名称
+		AppCount int
+		Running  int
+		CpuLoad  LoadInfo
+		MemLoad  LoadInfo
+		NetLoad  NetInfo // 网络速率之和, 仅 kubelet 来源支持
+		DiskLoad DiskInfo
+	}
+	// statAggregate 分组汇总的中间结果
+	statAggregate struct {
+		group    StatGroup
+		cpuTotal map[string]uint64 // key: 节点
+		memTotal map[string]uint64
+	}
+)
+
+// AggregateStats 按节点(HostIP), 标签(labelKey)或者空间汇总app的资源占用, namespaces 为空时汇总系统空间以及业务空间
+func (manage *ManagerK8s) AggregateStats(by, labelKey string, namespaces ...string) ([]StatGroup, error) {
+	logger.Info("aggregate container stats by %s %s 命令执行中...", by, labelKey)
+	if by != AggregateByNode && by != AggregateByLabel && by != AggregateByNamespace {
+		return nil, fmt.Errorf("unsupported aggregate key %q, expected node, label or namespace", by)
+	}
+	if by == AggregateByLabel && labelKey == "" {
+		return nil, fmt.Errorf("label key is required when aggregating by label")
+	}
+	if len(namespaces) == 0 {
+		namespaces = []string{manage.systemNamespace, manage.appNamespace}
+	}
+	groups := make(map[string]*statAggregate)
+	for _, namespace := range namespaces {
+		for _, item := range manage.containerCache.queryItems(namespace) {
+			var key string
+			switch by {
+			case AggregateByNode:
+				key = item.Info.HostIP
+				if key == "" {
+					key = item.Info.NodeName
+				}
+			case AggregateByLabel:
+				key = item.Info.Labels[labelKey]
+			case AggregateByNamespace:
+				key = namespace
+			}
+			aggregate, ok := groups[key]
+			if !ok {
+				aggregate = &statAggregate{group: StatGroup{Key: key}, cpuTotal: make(map[string]uint64), memTotal: make(map[string]uint64)}
+				groups[key] = aggregate
+			}
+			aggregate.add(item)
+		}
+	}
+	result := make([]StatGroup, 0, len(groups))
+	for _, aggregate := range groups {
+		result = append(result, aggregate.result())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result, nil
+}
+
+func (aggregate *statAggregate) add(item StatQueryItem) {
+	group := &aggregate.group
+	group.AppCount++
+	if item.Info.Status == RunningStatus {
+		group.Running++
+	}
+	stat := item.Stat
+	group.CpuLoad.Used += stat.CpuLoad.Used
+	group.MemLoad.Used += stat.MemLoad.Used
+	group.NetLoad.RxRate += stat.NetLoad.RxRate
+	group.NetLoad.TxRate += stat.NetLoad.TxRate
+	group.NetLoad.RxBytes += stat.NetLoad.RxBytes
+	group.NetLoad.TxBytes += stat.NetLoad.TxBytes
+	group.DiskLoad.RootfsUsed += stat.DiskLoad.RootfsUsed
+	group.DiskLoad.VolumeUsed += stat.DiskLoad.VolumeUsed
+	// 同一个节点上的app容量相同, 按节点去重
+	if node := item.Info.HostIP; node != "" && stat.CpuLoad.Total > 0 {
+		aggregate.cpuTotal[node] = stat.CpuLoad.Total
+		aggregate.memTotal[node] = stat.MemLoad.Total
+	}
+}
+
+func (aggregate *statAggregate) result() StatGroup {
+	group := aggregate.group
+	for _, total := range aggregate.cpuTotal {
+		group.CpuLoad.Total += total
+	}
+	for _, total := range aggregate.memTotal {
+		group.MemLoad.Total += total
+	}
+	group.CpuLoad.Ratio = loadRatio(group.CpuLoad.Used, group.CpuLoad.Total)
+	group.MemLoad.Ratio = loadRatio(group.MemLoad.Used, group.MemLoad.Total)
+	group.NetLoad.RxRate = round2(group.NetLoad.RxRate)
+	group.NetLoad.TxRate = round2(group.NetLoad.TxRate)
+	return group
+}
@@ -0,0 +1,487 @@
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	logger "github.com/alecthomas/log4go"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+/**
+ *    Description: 阈值告警: 基于资源信息缓存以及pod事件评估告警规则, 通过可插拔的通知器发送告警以及恢复通知, 支持带有效期的静默
+ *    Date: 2026/10/19
+ */
+
+const (
+	AlertMetricCpuRatio = "cpu_ratio" // 单位%
+	AlertMetricMemRatio = "mem_ratio" // 单位%
+	AlertMetricCpuUsed  = "cpu_used"  // 单位: 毫核
+	AlertMetricMemUsed  = "mem_used"  // 单位: MB
+	AlertMetricRestarts = "restarts"  // Duration 时间窗口内的重启次数
+
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+
+	AlertPending  = "pending"
+	AlertFiring   = "firing"
+	AlertResolved = "resolved"
+
+	NotificationAlert = "alert"
+	NotificationEvent = "event"
+
+	EventCrashed = "Crashed" // app的容器异常退出后重启
+	EventDeleted = "Deleted" // app的pod被删除
+
+	notificationQueueSize = 1000
+)
+
+var (
+	alertOperators = map[string]func(value, threshold float64) bool{
+		">":  func(value, threshold float64) bool { return value > threshold },
+		">=": func(value, threshold float64) bool { return value >= threshold },
+		"<":  func(value, threshold float64) bool { return value < threshold },
+		"<=": func(value, threshold float64) bool { return value <= threshold },
+	}
+	alertMetrics    = []string{AlertMetricCpuRatio, AlertMetricMemRatio, AlertMetricCpuUsed, AlertMetricMemUsed, AlertMetricRestarts}
+	alertSeverities = []string{SeverityInfo, SeverityWarning, SeverityCritical}
+)
+
+type (
+	// AlertRule 告警规则, 资源类指标需要持续满足 Duration 才会告警; restarts 指标的 Duration 为统计重启次数的时间窗口
+	AlertRule struct {
+		Name      string
+		Metric    string
+		Operator  string // >, >=, <, <=, 默认: >
+		Threshold float64
+		Duration  time.Duration
+		Severity  string            // info, warning, critical, 默认: warning
+		Namespace string            // 为空表示所有空间
+		Labels    map[string]string // 需要全部匹配的pod标签, 为空表示所有app
+	}
+	// Alert 未恢复的告警
+	Alert struct {
+		Rule      string
+		Severity  string
+		App       string
+		Namespace string
+		Node      string
+		Metric    string
+		Value     float64
+		Threshold float64
+		State     string // pending, firing
+		Since     time.Time
+		Labels    map[string]string // 最近一次评估时app的pod标签, 恢复时用于静默匹配以及通知
+	}
+	// Silence 静默, 有效期内匹配的告警不发送通知, 条件为空表示不限制
+	Silence struct {
+		ID        string
+		Rule      string
+		App       string
+		Labels    map[string]string
+		Comment   string
+		ExpiresAt time.Time
+	}
+	// Notification 通知, 告警以及pod生命周期事件共用
+	Notification struct {
+		Type      string            `json:"type"`   // alert, event
+		Status    string            `json:"status"` // 告警: firing, resolved; 事件: Crashed, Deleted
+		Severity  string            `json:"severity,omitempty"`
+		Rule      string            `json:"rule,omitempty"`
+		App       string            `json:"app"`
+		Namespace string            `json:"namespace"`
+		Node      string            `json:"node,omitempty"`
+		Metric    string            `json:"metric,omitempty"`
+		Value     float64           `json:"value,omitempty"`
+		Threshold float64           `json:"threshold,omitempty"`
+		Labels    map[string]string `json:"labels,omitempty"`
+		Message   string            `json:"message"`
+		StartsAt  time.Time         `json:"startsAt"`
+		At        time.Time         `json:"at"`
+	}
+	// Notifier 通知器, 所有通知在同一个后台协程中按顺序调用 Notify, 不会阻塞采集以及事件监听;
+	// Notify 阻塞时后续通知在队列中等待, 队列满时丢弃新的通知, 耗时的发送应当像 WebhookNotifier 一样在通知器内部排队
+	Notifier interface {
+		Name() string
+		Notify(notification Notification) error
+	}
+	// alertEngine 告警规则, 状态, 静默以及通知器
+	alertEngine struct {
+		lock      sync.Mutex
+		rules     []AlertRule
+		notifiers []Notifier
+		silences  map[string]Silence
+		states    map[string]*Alert      // key: 规则/空间/app
+		restarts  map[string][]time.Time // key: app_空间, 重启的时间
+		silenceID int
+		queue     chan Notification // 待发送的通知, 由 dispatch 协程发送
+	}
+)
+
+func newAlertEngine() *alertEngine {
+	engine := &alertEngine{silences: make(map[string]Silence), states: make(map[string]*Alert), restarts: make(map[string][]time.Time), queue: make(chan Notification, notificationQueueSize)}
+	go engine.dispatch()
+	return engine
+}
+
+func (rule *AlertRule) validate() error {
+	if rule.Name == "" {
+		return errors.New("alert rule name is required")
+	}
+	if !containsString(alertMetrics, rule.Metric) {
+		return fmt.Errorf("alert rule %s: unsupported metric %q, expected one of %v", rule.Name, rule.Metric, alertMetrics)
+	}
+	if _, ok := alertOperators[rule.Operator]; !ok {
+		return fmt.Errorf("alert rule %s: unsupported operator %q", rule.Name, rule.Operator)
+	}
+	if !containsString(alertSeverities, rule.Severity) {
+		return fmt.Errorf("alert rule %s: unsupported severity %q, expected one of %v", rule.Name, rule.Severity, alertSeverities)
+	}
+	if rule.Duration < 0 {
+		return fmt.Errorf("alert rule %s: duration must not be negative", rule.Name)
+	}
+	if rule.Metric == AlertMetricRestarts && rule.Duration == 0 {
+		return fmt.Errorf("alert rule %s: duration is required as the restart counting window", rule.Name)
+	}
+	return nil
+}
+
+// matchLabels pod标签是否包含所有的选择标签
+func matchLabels(selector, labels map[string]string) bool {
+	for key, value := range selector {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// SetAlertRules 设置告警规则, 替换原有的规则, 已删除规则的告警直接清除不发送恢复通知
+func (manage *ManagerK8s) SetAlertRules(rules ...AlertRule) error {
+	names := make(map[string]bool)
+	for i := range rules {
+		if rules[i].Operator == "" {
+			rules[i].Operator = ">"
+		}
+		if rules[i].Severity == "" {
+			rules[i].Severity = SeverityWarning
+		}
+		if err := rules[i].validate(); err != nil {
+			return err
+		}
+		if names[rules[i].Name] {
+			return fmt.Errorf("duplicate alert rule %s", rules[i].Name)
+		}
+		names[rules[i].Name] = true
+	}
+	engine := manage.alerts
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	engine.rules = rules
+	for key, alert := range engine.states {
+		if !names[alert.Rule] {
+			delete(engine.states, key)
+		}
+	}
+	return nil
+}
+
+// SetNotifiers 设置告警以及事件的通知器
+func (manage *ManagerK8s) SetNotifiers(notifiers ...Notifier) {
+	manage.alerts.lock.Lock()
+	defer manage.alerts.lock.Unlock()
+	manage.alerts.notifiers = notifiers
+}
+
+// AddSilence 添加静默, 返回静默的ID
+func (manage *ManagerK8s) AddSilence(silence Silence) (string, error) {
+	if !silence.ExpiresAt.After(time.Now()) {
+		return "", errors.New("silence expiresAt must be in the future")
+	}
+	engine := manage.alerts
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	engine.silenceID++
+	silence.ID = strconv.Itoa(engine.silenceID)
+	engine.silences[silence.ID] = silence
+	logger.Info("添加告警静默[%s]: 规则: %s, 容器: %s, 到期时间: %v", silence.ID, silence.Rule, silence.App, silence.ExpiresAt)
+	return silence.ID, nil
+}
+
+func (manage *ManagerK8s) DeleteSilence(id string) {
+	manage.alerts.lock.Lock()
+	defer manage.alerts.lock.Unlock()
+	delete(manage.alerts.silences, id)
+}
+
+// ListSilences 获取未到期的静默
+func (manage *ManagerK8s) ListSilences() []Silence {
+	engine := manage.alerts
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	engine.expireSilences(time.Now())
+	silences := make([]Silence, 0, len(engine.silences))
+	for _, silence := range engine.silences {
+		silences = append(silences, silence)
+	}
+	sort.Slice(silences, func(i, j int) bool { return silences[i].ExpiresAt.Before(silences[j].ExpiresAt) })
+	return silences
+}
+
+// ActiveAlerts 获取未恢复的告警(包括等待持续时间的告警)
+func (manage *ManagerK8s) ActiveAlerts() []Alert {
+	engine := manage.alerts
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	alerts := make([]Alert, 0, len(engine.states))
+	for _, alert := range engine.states {
+		alerts = append(alerts, *alert)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		if alerts[i].Rule != alerts[j].Rule {
+			return alerts[i].Rule < alerts[j].Rule
+		}
+		return alerts[i].Namespace+"/"+alerts[i].App < alerts[j].Namespace+"/"+alerts[j].App
+	})
+	return alerts
+}
+
+func (engine *alertEngine) expireSilences(now time.Time) {
+	for id, silence := range engine.silences {
+		if !silence.ExpiresAt.After(now) {
+			delete(engine.silences, id)
+		}
+	}
+}
+
+// silenced 告警是否被静默
+func (engine *alertEngine) silenced(alert *Alert, labels map[string]string, now time.Time) bool {
+	for _, silence := range engine.silences {
+		if silence.ExpiresAt.After(now) && (silence.Rule == "" || silence.Rule == alert.Rule) &&
+			(silence.App == "" || silence.App == alert.App) && matchLabels(silence.Labels, labels) {
+			return true
+		}
+	}
+	return false
+}
+
+// recordRestarts 记录app的重启, 由pod事件更新容器信息时调用
+func (engine *alertEngine) recordRestarts(key string, count int, now time.Time) {
+	engine.lock.Lock()
+	defer engine.lock.Unlock()
+	for i := 0; i < count; i++ {
+		engine.restarts[key] = append(engine.restarts[key], now)
+	}
+}
+
+// restartsWithin 时间窗口内的重启次数
+func (engine *alertEngine) restartsWithin(key string, window time.Duration, now time.Time) int {
+	times := engine.restarts[key]
+	count := 0
+	for _, at := range times {
+		if now.Sub(at) <= window {
+			count++
+		}
+	}
+	return count
+}
+
+// metricValue 获取app的指标值
+func (engine *alertEngine) metricValue(rule AlertRule, key string, item StatQueryItem, now time.Time) float64 {
+	switch rule.Metric {
+	case AlertMetricCpuRatio:
+		return item.Stat.CpuLoad.Ratio
+	case AlertMetricMemRatio:
+		return item.Stat.MemLoad.Ratio
+	case AlertMetricCpuUsed:
+		return float64(item.Stat.CpuLoad.Used)
+	case AlertMetricMemUsed:
+		return float64(item.Stat.MemLoad.Used)
+	case AlertMetricRestarts:
+		return float64(engine.restartsWithin(key, rule.Duration, now))
+	}
+	return 0
+}
+
+// evaluateAlerts 评估空间下所有app的告警规则, 在每次采集资源信息以及app重启后执行
+func (manage *ManagerK8s) evaluateAlerts(namespace string) {
+	engine := manage.alerts
+	if engine == nil {
+		return
+	}
+	now := time.Now()
+	items := manage.containerCache.queryItems(namespace)
+	var notifications []Notification
+	engine.lock.Lock()
+	engine.expireSilences(now)
+	// 只有采集到新的资源信息并且不再满足条件时才恢复, 缺少资源信息(尚未采集, 暂停或者采集失败)时保持告警状态
+	var resolved []string
+	var maxWindow time.Duration
+	for _, rule := range engine.rules {
+		if rule.Metric == AlertMetricRestarts && rule.Duration > maxWindow {
+			maxWindow = rule.Duration
+		}
+		if rule.Namespace != "" && rule.Namespace != namespace {
+			continue
+		}
+		for _, item := range items {
+			if !matchLabels(rule.Labels, item.Info.Labels) {
+				continue
+			}
+			if rule.Metric != AlertMetricRestarts && !manage.freshStat(item, namespace) {
+				continue
+			}
+			stateKey := rule.Name + "/" + namespace + "/" + item.Stat.Name
+			value := engine.metricValue(rule, item.Stat.Name+"_"+namespace, item, now)
+			alert, exists := engine.states[stateKey]
+			if !alertOperators[rule.Operator](value, rule.Threshold) {
+				if exists {
+					resolved = append(resolved, stateKey)
+				}
+				continue
+			}
+			if !exists {
+				alert = &Alert{Rule: rule.Name, Severity: rule.Severity, App: item.Stat.Name, Namespace: namespace, Metric: rule.Metric, Threshold: rule.Threshold, State: AlertPending, Since: now}
+				engine.states[stateKey] = alert
+			}
+			alert.Value, alert.Node, alert.Labels = value, item.Info.HostIP, item.Info.Labels
+			// restarts 指标在时间窗口内统计, 满足条件立即告警
+			if alert.State == AlertPending && (rule.Metric == AlertMetricRestarts || now.Sub(alert.Since) >= rule.Duration) {
+				alert.State = AlertFiring
+				if !engine.silenced(alert, alert.Labels, now) {
+					notifications = append(notifications, alertNotification(alert, alert.Labels, AlertFiring, now))
+				}
+			}
+		}
+	}
+	for _, key := range resolved {
+		notifications = append(notifications, engine.resolve(key, engine.states[key], now)...)
+	}
+	// 清理时间窗口外的重启记录
+	for key, times := range engine.restarts {
+		for len(times) > 0 && now.Sub(times[0]) > maxWindow {
+			times = times[1:]
+		}
+		if len(times) == 0 {
+			delete(engine.restarts, key)
+		} else {
+			engine.restarts[key] = times
+		}
+	}
+	engine.lock.Unlock()
+	for _, notification := range notifications {
+		engine.enqueue(notification)
+	}
+}
+
+// freshStat app是否有本次采集的资源信息: 正常采集中并且已缓存资源信息(连续采集失败达到容忍次数后删除)
+func (manage *ManagerK8s) freshStat(item StatQueryItem, namespace string) bool {
+	if item.Stat.CpuLoad.Total == 0 && item.Stat.MemLoad.Total == 0 {
+		return false
+	}
+	return manage.monitors.collecting(item.Stat.Name + "_" + namespace)
+}
+
+// resolve 删除告警状态, 触发中的告警返回恢复通知; 调用方持有锁
+func (engine *alertEngine) resolve(key string, alert *Alert, now time.Time) []Notification {
+	delete(engine.states, key)
+	if alert.State == AlertFiring && !engine.silenced(alert, alert.Labels, now) {
+		return []Notification{alertNotification(alert, alert.Labels, AlertResolved, now)}
+	}
+	return nil
+}
+
+// forgetApp app(StatefulSet)删除时恢复app的所有告警
+func (manage *ManagerK8s) forgetApp(name, namespace string) {
+	engine := manage.alerts
+	if engine == nil {
+		return
+	}
+	now := time.Now()
+	var notifications []Notification
+	engine.lock.Lock()
+	for key, alert := range engine.states {
+		if alert.App == name && alert.Namespace == namespace {
+			notifications = append(notifications, engine.resolve(key, alert, now)...)
+		}
+	}
+	engine.lock.Unlock()
+	for _, notification := range notifications {
+		engine.enqueue(notification)
+	}
+}
+
+func alertNotification(alert *Alert, labels map[string]string, status string, now time.Time) Notification {
+	message := fmt.Sprintf("【容器: %s】告警规则: %s, %s 当前值: %v, 阈值: %v", alert.App, alert.Rule, alert.Metric, alert.Value, alert.Threshold)
+	if status == AlertResolved {
+		message = fmt.Sprintf("【容器: %s】告警规则: %s 已恢复", alert.App, alert.Rule)
+	}
+	return Notification{
+		Type: NotificationAlert, Status: status, Severity: alert.Severity, Rule: alert.Rule, App: alert.App, Namespace: alert.Namespace, Node: alert.Node,
+		Metric: alert.Metric, Value: alert.Value, Threshold: alert.Threshold, Labels: labels, Message: message, StartsAt: alert.Since, At: now,
+	}
+}
+
+// NotifyEvent 发送生命周期事件通知到所有的通知器, 未设置时间时使用当前时间
+func (manage *ManagerK8s) NotifyEvent(notification Notification) {
+	if manage.alerts == nil {
+		return
+	}
+	notification.Type = NotificationEvent
+	if notification.At.IsZero() {
+		notification.At = time.Now()
+	}
+	manage.alerts.enqueue(notification)
+}
+
+// crashNotification app重启的事件通知, 原因取重启容器上次退出的原因
+func crashNotification(namespace string, prev, info ContainerInfo) Notification {
+	reason, restarted := "", 0
+	prevCounts := make(map[string]int, len(prev.Containers))
+	for _, container := range prev.Containers {
+		prevCounts[container.Name] = container.ReStartCount
+	}
+	for _, container := range info.Containers {
+		if container.ReStartCount > prevCounts[container.Name] {
+			restarted += container.ReStartCount - prevCounts[container.Name]
+			if reason == "" {
+				reason = container.Name + ": " + container.LastReason
+			}
+		}
+	}
+	return Notification{
+		Status: EventCrashed, Severity: SeverityWarning, App: info.Name, Namespace: namespace, Node: info.HostIP, Labels: info.Labels,
+		Message: fmt.Sprintf("【容器: %s】异常重启 %d 次, 累计重启 %d 次, 退出原因: %s", info.Name, restarted, info.ReStartCount, reason),
+	}
+}
+
+// enqueue 通知加入发送队列, 不阻塞调用方, 队列满时丢弃
+func (engine *alertEngine) enqueue(notification Notification) {
+	select {
+	case engine.queue <- notification:
+	default:
+		logger.Warn("通知队列已满, 丢弃通知: %s", notification.Message)
+	}
+}
+
+// dispatch 按顺序发送队列中的通知, 发送时使用最新的通知器
+func (engine *alertEngine) dispatch() {
+	for notification := range engine.queue {
+		engine.lock.Lock()
+		notifiers := engine.notifiers
+		engine.lock.Unlock()
+		notify(notifiers, notification)
+	}
+}
+
+// notify 发送通知到所有的通知器, 失败时记录日志
+func notify(notifiers []Notifier, notification Notification) {
+	for _, notifier := range notifiers {
+		if err := notifier.Notify(notification); err != nil {
+			logger.Warn("通知器[%s]发送通知失败: %v, 通知: %s", notifier.Name(), err, notification.Message)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	logger "github.com/alecthomas/log4go"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metricsv1beta1 "k8s.io/metrics/pkg/apis/metrics/v1beta1"
+	"strings"
+	"sync"
+	"time"
+)
+
+/**
+ *    Description: 批量采集容器资源信息, 每个空间一个定时器, 每次通过一次 PodMetrics List 更新空间下所有app的资源信息
+ *    Date: 2026/10/19
+ */
+
+const (
+	nodeCapacityTTL     = time.Minute // 节点容量缓存的有效期
+	nodeCapacityRetryIn = 10 * time.Second
+)
+
+type (
+	// nodeCapacity 节点容量, cpu单位: 毫核, 内存单位: byte
+	nodeCapacity struct {
+		name   string // 节点名称
+		cpu    uint64
+		memory uint64
+	}
+	// capacityCache 节点容量缓存, 通过一次 Nodes List 刷新, key为节点名称以及节点地址
+	capacityCache struct {
+		lock      sync.RWMutex
+		nodes     map[string]nodeCapacity
+		names     []string
+		updatedAt time.Time
+	}
+	// podUsage pod的资源占用, 与监控数据的来源(metrics-server, kubelet)无关
+	podUsage struct {
+		name       string
+		node       string // 节点名称, metrics-server 不提供
+		timestamp  time.Time
+		containers []containerUsage
+		network    *networkUsage // metrics-server 不提供
+		volumes    []volumeUsage // metrics-server 不提供
+	}
+	// containerUsage 容器的资源占用, cpu单位: 毫核, 内存以及磁盘单位: byte
+	containerUsage struct {
+		name   string
+		cpu    uint64
+		memory uint64
+		rootfs uint64
+	}
+	// networkUsage pod的累计网络流量, 单位: byte
+	networkUsage struct {
+		at     time.Time
+		rx, tx uint64
+	}
+	volumeUsage struct {
+		name           string
+		used, capacity uint64
+	}
+)
+
+// nodeCapacity 获取节点容量, 缓存过期或者节点不存在时刷新缓存, 刷新间隔不小于 nodeCapacityRetryIn
+func (api *k8sApi) nodeCapacity(name string) (nodeCapacity, error) {
+	api.capacity.lock.RLock()
+	capacity, ok := api.capacity.nodes[name]
+	age := time.Since(api.capacity.updatedAt)
+	api.capacity.lock.RUnlock()
+	if (ok && age < nodeCapacityTTL) || (!ok && age < nodeCapacityRetryIn) {
+		if !ok {
+			return nodeCapacity{}, fmt.Errorf("node %s not found", name)
+		}
+		return capacity, nil
+	}
+	if err := api.refreshCapacity(); err != nil {
+		return capacity, err
+	}
+	api.capacity.lock.RLock()
+	defer api.capacity.lock.RUnlock()
+	if capacity, ok = api.capacity.nodes[name]; !ok {
+		return nodeCapacity{}, fmt.Errorf("node %s not found", name)
+	}
+	return capacity, nil
+}
+
+// nodeNames 获取所有节点的名称, 使用节点容量缓存
+func (api *k8sApi) nodeNames() ([]string, error) {
+	api.capacity.lock.RLock()
+	names, age := api.capacity.names, time.Since(api.capacity.updatedAt)
+	api.capacity.lock.RUnlock()
+	if age < nodeCapacityTTL {
+		return names, nil
+	}
+	if err := api.refreshCapacity(); err != nil {
+		return names, err
+	}
+	api.capacity.lock.RLock()
+	defer api.capacity.lock.RUnlock()
+	return api.capacity.names, nil
+}
+
+func (api *k8sApi) refreshCapacity() error {
+	nodes, err := api.client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		internalMetrics.apiError("node_list")
+		return err
+	}
+	capacities := make(map[string]nodeCapacity, len(nodes.Items))
+	names := make([]string, 0, len(nodes.Items))
+	for _, node := range nodes.Items {
+		totalCPU := node.Status.Capacity[corev1.ResourceCPU]
+		totalMemory := node.Status.Capacity[corev1.ResourceMemory]
+		capacity := nodeCapacity{name: node.Name, cpu: uint64(totalCPU.MilliValue()), memory: uint64(totalMemory.Value())}
+		capacities[node.Name] = capacity
+		names = append(names, node.Name)
+		// pod的 HostIP 为节点地址, 节点名称不是地址时同样可以查询
+		for _, address := range node.Status.Addresses {
+			if address.Type == corev1.NodeInternalIP {
+				capacities[address.Address] = capacity
+			}
+		}
+	}
+	api.capacity.lock.Lock()
+	api.capacity.nodes = capacities
+	api.capacity.names = names
+	api.capacity.updatedAt = time.Now()
+	api.capacity.lock.Unlock()
+	return nil
+}
+
+// listPodUsage 获取空间下所有pod的资源占用, 根据监控数据的来源选择 metrics-server 或者 kubelet
+func (api *k8sApi) listPodUsage(namespace string) ([]podUsage, error) {
+	if api.source() == MetricsSourceKubelet {
+		return api.listKubeletPodUsage(namespace)
+	}
+	podMetrics, err := api.metric.MetricsV1beta1().PodMetricses(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: excludePrePull})
+	if err != nil {
+		internalMetrics.apiError("pod_metrics_list")
+		return nil, err
+	}
+	usages := make([]podUsage, 0, len(podMetrics.Items))
+	for _, podMetric := range podMetrics.Items {
+		usages = append(usages, podMetricToUsage(podMetric))
+	}
+	return usages, nil
+}
+
+func podMetricToUsage(podMetric metricsv1beta1.PodMetrics) podUsage {
+	usage := podUsage{name: podMetric.Name, timestamp: podMetric.Timestamp.Time}
+	for _, container := range podMetric.Containers {
+		resourceCPU := container.Usage[corev1.ResourceCPU]
+		resourceMemory := container.Usage[corev1.ResourceMemory]
+		usage.containers = append(usage.containers, containerUsage{name: container.Name, cpu: uint64(resourceCPU.MilliValue()), memory: uint64(resourceMemory.Value())})
+	}
+	return usage
+}
+
+// podAppName pod名称转换为app名称, 业务空间的pod名称为: app名称-0
+func (api *k8sApi) podAppName(podName, namespace string) string {
+	if namespace == api.appNamespace {
+		return strings.TrimSuffix(podName, "-0")
+	}
+	return podName
+}
+
+// collectMetrics 按空间的采集周期批量采集所有app的资源信息, 失败时退避重试, 管理器停止时退出
+func (manage *ManagerK8s) collectMetrics(namespace string) {
+	timer := time.NewTimer(manage.GetMonitorOptions(namespace).Interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-timer.C:
+			err := manage.collectOnce(namespace)
+			interval, exceeded := manage.monitors.nextInterval(namespace, err)
+			// 连续失败达到容忍次数, 清除过期的资源信息, 暂停采集的app保留
+			if exceeded {
+				logger.Warn("【空间: %s】资源信息采集连续失败达到容忍次数, 清除资源信息", namespace)
+				for _, name := range manage.containerCache.statNames(namespace) {
+					if manage.monitors.collecting(name + "_" + namespace) {
+						manage.containerCache.delContainerStatInfo(name + "_" + namespace)
+					}
+				}
+			}
+			timer.Reset(interval)
+		case <-manage.eventExitCh:
+			logger.Info("【空间: %s】停止采集容器相关资源信息！", namespace)
+			return
+		}
+	}
+}
+
+// collectOnce 通过一次 PodMetrics List(kubelet 来源时每个节点一次 Summary)更新空间下所有正常采集的app的资源信息,
+// 连续缺少监控数据达到容忍次数的app删除资源信息
+func (manage *ManagerK8s) collectOnce(namespace string) error {
+	start := time.Now()
+	defer func() { internalMetrics.observePoll(namespace, time.Since(start)) }()
+	usages, err := manage.api.listPodUsage(namespace)
+	if err != nil {
+		return err
+	}
+	isSys := namespace == manage.systemNamespace
+	collected := make(map[string]bool, len(usages))
+	for _, usage := range usages {
+		name := manage.api.podAppName(usage.name, namespace)
+		if !manage.monitors.collecting(name + "_" + namespace) {
+			continue
+		}
+		node := usage.node
+		if node == "" {
+			containerInfo, err := manage.GetCacheContainerInfo(name, isSys)
+			if err != nil {
+				logger.Info("【容器: %v】获取容器信息异常: %v", name, err)
+				continue
+			}
+			node = containerInfo.HostIP
+		}
+		capacity, err := manage.api.nodeCapacity(node)
+		if err != nil {
+			logger.Info("【容器: %v】获取节点: %s 容量异常: %v", name, node, err)
+			continue
+		}
+		collected[name] = true
+		manage.monitors.missed(name+"_"+namespace, namespace, false)
+		stat := podUsageToStatInfo(name, usage, capacity)
+		if prev, ok := manage.containerCache.getCacheStatInfo(name + "_" + namespace); ok {
+			stat.NetLoad = netRate(prev.NetLoad, stat.NetLoad)
+		}
+		if !manage.storeStatInfo(name+"_"+namespace, stat) {
+			continue
+		}
+		at := usage.timestamp
+		if at.IsZero() {
+			at = time.Now()
+		}
+		manage.containerCache.recordHistory(name+"_"+namespace, stat, at, manage.options)
+	}
+	for _, name := range manage.containerCache.statNames(namespace) {
+		key := name + "_" + namespace
+		if !collected[name] && manage.monitors.collecting(key) && manage.monitors.missed(key, namespace, true) {
+			manage.containerCache.delContainerStatInfo(key)
+		}
+	}
+	manage.evaluateAlerts(namespace)
+	return nil
+}
+
+// netRate 根据上一次采集的累计流量计算速率, 计数器重置(pod重启)时速率为0; kubelet的统计未更新(时间相同)时沿用上一次的速率
+func netRate(prev, cur NetInfo) NetInfo {
+	if !prev.At.IsZero() && cur.At.Equal(prev.At) && cur.RxBytes == prev.RxBytes && cur.TxBytes == prev.TxBytes {
+		cur.RxRate, cur.TxRate = prev.RxRate, prev.TxRate
+		return cur
+	}
+	seconds := cur.At.Sub(prev.At).Seconds()
+	if prev.At.IsZero() || seconds <= 0 || cur.RxBytes < prev.RxBytes || cur.TxBytes < prev.TxBytes {
+		return cur
+	}
+	cur.RxRate = round2(float64(cur.RxBytes-prev.RxBytes) / seconds)
+	cur.TxRate = round2(float64(cur.TxBytes-prev.TxBytes) / seconds)
+	return cur
+}
@@ -0,0 +1,302 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	"sigs.k8s.io/yaml"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/**
+ *    Description: docker-compose 文件转换为 CreateReqInfo, 用于迁移原有的 docker-compose 服务
+ *    Date: 2026/10/19
+ */
+
+type (
+	// ComposeIssue 转换时不支持或者被忽略的配置
+	ComposeIssue struct {
+		Service string // 服务名称, 顶层配置为空
+		Key     string
+		Reason  string
+	}
+	// ComposeReport 转换报告
+	ComposeReport struct {
+		Issues []ComposeIssue
+	}
+)
+
+// composeTopKeys 顶层支持的配置, 命名卷以及网络不转换
+var composeTopKeys = map[string]bool{"version": true, "name": true, "services": true}
+
+// composeServiceKeys 服务支持的配置
+var composeServiceKeys = map[string]bool{
+	"image": true, "container_name": true, "environment": true, "ports": true, "volumes": true, "restart": true,
+	"privileged": true, "network_mode": true, "command": true, "entrypoint": true, "working_dir": true,
+	"labels": true, "cap_add": true, "cap_drop": true, "ulimits": true, "mac_address": true, "user": true,
+}
+
+func (report *ComposeReport) add(service, key, format string, args ...interface{}) {
+	report.Issues = append(report.Issues, ComposeIssue{Service: service, Key: key, Reason: fmt.Sprintf(format, args...)})
+}
+
+// String 转换报告的描述, 每行一个配置
+func (report *ComposeReport) String() string {
+	var lines []string
+	for _, issue := range report.Issues {
+		if issue.Service == "" {
+			lines = append(lines, fmt.Sprintf("%s: %s", issue.Key, issue.Reason))
+		} else {
+			lines = append(lines, fmt.Sprintf("services.%s.%s: %s", issue.Service, issue.Key, issue.Reason))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ConvertCompose 将 docker-compose 文件转换为创建信息, 所有服务固定在 nodeName 节点(为空时自动调度),
+// 不支持的配置记录在报告中, 转换结果需要通过 Validate 或者 dry-run 创建进一步校验
+func ConvertCompose(data []byte, nodeName string) ([]CreateReqInfo, *ComposeReport, error) {
+	var compose map[string]interface{}
+	// 数字使用 json.Number, 避免端口以及 ulimit 等大数字被格式化为科学计数法
+	if err := yaml.Unmarshal(data, &compose, func(d *json.Decoder) *json.Decoder { d.UseNumber(); return d }); err != nil {
+		return nil, nil, err
+	}
+	report := &ComposeReport{}
+	for _, key := range sortedMapKeys(compose) {
+		if !composeTopKeys[key] {
+			report.add("", key, "not supported, ignored")
+		}
+	}
+	services, ok := compose["services"].(map[string]interface{})
+	if !ok {
+		return nil, nil, fmt.Errorf("compose file has no services")
+	}
+	var infos []CreateReqInfo
+	for _, name := range sortedMapKeys(services) {
+		service, ok := services[name].(map[string]interface{})
+		if !ok {
+			return nil, nil, fmt.Errorf("service %q is not a mapping", name)
+		}
+		infos = append(infos, convertComposeService(name, service, nodeName, report))
+	}
+	return infos, report, nil
+}
+
+func convertComposeService(name string, service map[string]interface{}, nodeName string, report *ComposeReport) CreateReqInfo {
+	info := CreateReqInfo{Name: composeName(name), NodeName: nodeName, Restart: RESTART_Always}
+	for _, key := range sortedMapKeys(service) {
+		if !composeServiceKeys[key] {
+			report.add(name, key, "not supported, ignored")
+		}
+	}
+	if containerName, ok := service["container_name"].(string); ok {
+		info.Name = composeName(containerName)
+	}
+	info.Image, _ = service["image"].(string)
+	if info.Image == "" {
+		report.add(name, "image", "image is required, build is not supported")
+	}
+	for _, pair := range composeKeyValues(service["environment"]) {
+		info.Env = append(info.Env, EnvInfo{Key: pair[0], Value: pair[1]})
+	}
+	for _, pair := range composeKeyValues(service["labels"]) {
+		info.Label = append(info.Label, LabelInfo{Key: pair[0], Value: pair[1]})
+	}
+	if mode, ok := service["network_mode"].(string); ok {
+		if mode == "host" {
+			info.HostNetwork = true
+		} else if mode != "bridge" {
+			report.add(name, "network_mode", "network mode %q not supported, bridge is used", mode)
+		}
+	}
+	if privileged, _ := service["privileged"].(bool); privileged {
+		info.Env = append(info.Env, EnvInfo{Key: ENV_PRIVILEGED, Value: "true"})
+	}
+	if mac, ok := service["mac_address"].(string); ok {
+		info.Env = append(info.Env, EnvInfo{Key: ENV_MACADDRESS, Value: mac})
+	}
+	if ulimit := composeUlimits(name, service["ulimits"], report); ulimit != "" {
+		info.Env = append(info.Env, EnvInfo{Key: ENV_ULIMIT_NAME, Value: ulimit})
+	}
+	if restart, ok := service["restart"].(string); ok && restart != "always" && restart != "unless-stopped" {
+		report.add(name, "restart", "restart policy %q not supported, Always is used", restart)
+	}
+	info.Port = composePorts(name, service["ports"], info.HostNetwork, report)
+	info.Volume = composeVolumes(name, service["volumes"], report)
+	// compose 的 entrypoint 对应 k8s 的 command, command 对应 args
+	info.Command = composeCommand(service["entrypoint"])
+	info.Args = composeCommand(service["command"])
+	info.WorkingDir, _ = service["working_dir"].(string)
+	info.Security.CapAdd = composeStrings(service["cap_add"])
+	info.Security.CapDrop = composeStrings(service["cap_drop"])
+	if user, ok := service["user"]; ok {
+		uid, gid, _ := strings.Cut(fmt.Sprint(user), ":")
+		if id, err := strconv.ParseInt(uid, 10, 64); err == nil {
+			info.Security.RunAsUser = &id
+		} else {
+			report.add(name, "user", "user %q must be a numeric uid", uid)
+		}
+		if id, err := strconv.ParseInt(gid, 10, 64); err == nil {
+			info.Security.RunAsGroup = &id
+		} else if gid != "" {
+			report.add(name, "user", "group %q must be a numeric gid", gid)
+		}
+	}
+	return info
+}
+
+// composePorts 支持短格式: [ip:][host:]container[/protocol] 以及长格式: {target, published, protocol}, 不支持端口范围
+func composePorts(service string, value interface{}, hostNetwork bool, report *ComposeReport) []PortInfo {
+	var ports []PortInfo
+	items, _ := value.([]interface{})
+	for _, item := range items {
+		port := PortInfo{Protocol: "TCP"}
+		var inner, outer string
+		if long, ok := item.(map[string]interface{}); ok {
+			inner, outer = fmt.Sprint(long["target"]), ""
+			if published, ok := long["published"]; ok {
+				outer = fmt.Sprint(published)
+			}
+			if protocol, ok := long["protocol"].(string); ok {
+				port.Protocol = strings.ToUpper(protocol)
+			}
+		} else {
+			short := fmt.Sprint(item)
+			if mapping, protocol, ok := strings.Cut(short, "/"); ok {
+				short, port.Protocol = mapping, strings.ToUpper(protocol)
+			}
+			parts := strings.Split(short, ":")
+			inner = parts[len(parts)-1]
+			if len(parts) > 1 {
+				outer = parts[len(parts)-2]
+			}
+			if len(parts) > 2 {
+				report.add(service, "ports", "host ip of %q not supported, all addresses are used", fmt.Sprint(item))
+			}
+		}
+		innerPort, err1 := strconv.ParseUint(inner, 10, 16)
+		outerPort, err2 := strconv.ParseUint(outer, 10, 16)
+		if err1 != nil || (outer != "" && err2 != nil) {
+			report.add(service, "ports", "port %v not supported, ignored", item)
+			continue
+		}
+		port.InnerPort, port.OuterPort = uint16(innerPort), uint16(outerPort)
+		if hostNetwork {
+			port.OuterPort = 0
+		} else if port.OuterPort == 0 {
+			report.add(service, "ports", "port %v has no published port, not exposed on the host", item)
+		}
+		ports = append(ports, port)
+	}
+	return ports
+}
+
+// composeVolumes 仅支持主机目录的绑定挂载, 命名卷以及tmpfs不转换, 只读挂载按读写挂载处理
+func composeVolumes(service string, value interface{}, report *ComposeReport) []VolumeInfo {
+	var volumes []VolumeInfo
+	items, _ := value.([]interface{})
+	for _, item := range items {
+		var source, target, mode string
+		if long, ok := item.(map[string]interface{}); ok {
+			if volumeType, _ := long["type"].(string); volumeType != "bind" {
+				report.add(service, "volumes", "volume type %q not supported, ignored", volumeType)
+				continue
+			}
+			source, _ = long["source"].(string)
+			target, _ = long["target"].(string)
+			if readOnly, _ := long["read_only"].(bool); readOnly {
+				mode = "ro"
+			}
+		} else {
+			parts := strings.Split(fmt.Sprint(item), ":")
+			if len(parts) < 2 {
+				report.add(service, "volumes", "anonymous volume %q not supported, ignored", parts[0])
+				continue
+			}
+			source, target = parts[0], parts[1]
+			if len(parts) > 2 {
+				mode = parts[2]
+			}
+		}
+		if !strings.HasPrefix(source, "/") {
+			report.add(service, "volumes", "volume source %q must be an absolute host path, named and relative volumes are not supported", source)
+			continue
+		}
+		if strings.Contains(mode, "ro") {
+			report.add(service, "volumes", "read only mount %s is mounted read-write", target)
+		}
+		volumes = append(volumes, VolumeInfo{InnerPath: target, OuterPath: source})
+	}
+	return volumes
+}
+
+// composeUlimits 转换为 ULIMIT 环境变量的格式: nofile=soft:hard,nproc=limit
+func composeUlimits(service string, value interface{}, report *ComposeReport) string {
+	ulimits, _ := value.(map[string]interface{})
+	var items []string
+	for _, name := range sortedMapKeys(ulimits) {
+		if !ulimitNames[name] {
+			report.add(service, "ulimits", "ulimit %q not supported, ignored", name)
+			continue
+		}
+		if limit, ok := ulimits[name].(map[string]interface{}); ok {
+			items = append(items, fmt.Sprintf("%s=%v:%v", name, limit["soft"], limit["hard"]))
+		} else {
+			items = append(items, fmt.Sprintf("%s=%v", name, ulimits[name]))
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+// composeCommand 字符串格式按空白分隔, 不支持引号, 需要引号时请使用列表格式
+func composeCommand(value interface{}) []string {
+	if command, ok := value.(string); ok {
+		return strings.Fields(command)
+	}
+	return composeStrings(value)
+}
+
+func composeStrings(value interface{}) []string {
+	items, _ := value.([]interface{})
+	var list []string
+	for _, item := range items {
+		list = append(list, fmt.Sprint(item))
+	}
+	return list
+}
+
+// composeKeyValues 支持 map 格式以及 KEY=VALUE 的列表格式
+func composeKeyValues(value interface{}) [][2]string {
+	var pairs [][2]string
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedMapKeys(v) {
+			if v[key] == nil {
+				pairs = append(pairs, [2]string{key, ""})
+			} else {
+				pairs = append(pairs, [2]string{key, fmt.Sprint(v[key])})
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			key, value, _ := strings.Cut(fmt.Sprint(item), "=")
+			pairs = append(pairs, [2]string{key, value})
+		}
+	}
+	return pairs
+}
+
+// composeName 服务名称转换为k8s的名称: 小写, 下划线替换为中划线
+func composeName(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "_", "-")
+}
+
+func sortedMapKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
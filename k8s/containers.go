@@ -0,0 +1,130 @@
+package k8s
+
+import (
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+/**
+ *    Description: 多容器pod: init容器以及边车容器
+ *    Date: 2026/10/19
+ */
+
+// extraContainer 转换init容器或者边车容器, 卷名称使用: app名称-容器名称-data-序号
+func extraContainer(appName string, hostNetwork bool, spec ContainerSpecInfo) (corev1.Container, []corev1.Volume) {
+	container := corev1.Container{
+		Name:       spec.Name,
+		Image:      spec.Image,
+		Command:    spec.Command,
+		Args:       spec.Args,
+		WorkingDir: spec.WorkingDir,
+		Resources:  resourceRequirements(spec.Resource),
+	}
+	for _, envInfo := range spec.Env {
+		container.Env = append(container.Env, corev1.EnvVar{Name: envInfo.Key, Value: envInfo.Value})
+	}
+	for _, port := range spec.Port {
+		if hostNetwork {
+			container.Ports = append(container.Ports, corev1.ContainerPort{ContainerPort: int32(port.InnerPort), Protocol: corev1.Protocol(port.Protocol)})
+		} else {
+			container.Ports = append(container.Ports, corev1.ContainerPort{ContainerPort: int32(port.InnerPort), HostPort: int32(port.OuterPort), Protocol: corev1.Protocol(port.Protocol)})
+		}
+	}
+	var volumes []corev1.Volume
+	for i, volume := range spec.Volume {
+		volumeName := fmt.Sprintf("%s-%s-data-%d", appName, spec.Name, i)
+		volumes = append(volumes, corev1.Volume{Name: volumeName, VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: volume.OuterPath, Type: (*corev1.HostPathType)(proto.String(string(corev1.HostPathDirectoryOrCreate)))}}})
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{Name: volumeName, MountPath: volume.InnerPath})
+	}
+	return container, volumes
+}
+
+// applyExtraContainers 将init容器以及边车容器转换到创建信息中
+func applyExtraContainers(createInfo *ContainerCreateInfo, info *CreateReqInfo) {
+	for _, spec := range info.InitContainers {
+		container, volumes := extraContainer(info.Name, info.HostNetwork, spec)
+		createInfo.InitContainers = append(createInfo.InitContainers, container)
+		createInfo.Volumes = append(createInfo.Volumes, volumes...)
+	}
+	for _, spec := range info.Sidecars {
+		container, volumes := extraContainer(info.Name, info.HostNetwork, spec)
+		createInfo.Sidecars = append(createInfo.Sidecars, container)
+		createInfo.Volumes = append(createInfo.Volumes, volumes...)
+	}
+}
+
+// allContainerSpecs 返回主容器, init容器以及边车容器的镜像, 端口以及卷
+func allContainerSpecs(info *CreateReqInfo) []ContainerSpecInfo {
+	specs := []ContainerSpecInfo{{Name: info.Name, Image: info.Image, Port: info.Port, Volume: info.Volume}}
+	specs = append(specs, info.InitContainers...)
+	return append(specs, info.Sidecars...)
+}
+
+func validateExtraContainers(info *CreateReqInfo) field.ErrorList {
+	var allErrs field.ErrorList
+	names := map[string]bool{info.Name: true}
+	validate := func(specs []ContainerSpecInfo, fldPath *field.Path) {
+		for i, spec := range specs {
+			idxPath := fldPath.Index(i)
+			if spec.Name == "" {
+				allErrs = append(allErrs, field.Required(idxPath.Child("Name"), ""))
+			} else {
+				for _, msg := range validation.IsDNS1123Label(spec.Name) {
+					allErrs = append(allErrs, field.Invalid(idxPath.Child("Name"), spec.Name, msg))
+				}
+				if names[spec.Name] {
+					allErrs = append(allErrs, field.Duplicate(idxPath.Child("Name"), spec.Name))
+				}
+				names[spec.Name] = true
+			}
+			if spec.Image == "" {
+				allErrs = append(allErrs, field.Required(idxPath.Child("Image"), ""))
+			} else if !imageReferenceRegexp.MatchString(spec.Image) {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("Image"), spec.Image, "invalid image reference, expected [registry[:port]/]repository[:tag][@sha256:digest]"))
+			}
+			if spec.WorkingDir != "" {
+				allErrs = append(allErrs, validateAbsPath(spec.WorkingDir, idxPath.Child("WorkingDir"))...)
+			}
+			allErrs = append(allErrs, validateEnv(spec.Env, idxPath.Child("Env"))...)
+			allErrs = append(allErrs, validatePorts(spec.Port, info.HostNetwork, idxPath.Child("Port"))...)
+			allErrs = append(allErrs, validateVolumes(spec.Volume, idxPath.Child("Volume"))...)
+			allErrs = append(allErrs, validateResource(spec.Resource, idxPath.Child("Resource"))...)
+		}
+	}
+	validate(info.InitContainers, field.NewPath("InitContainers"))
+	validate(info.Sidecars, field.NewPath("Sidecars"))
+	// 主容器以及边车容器同时运行, 边车容器的端口不能与主容器以及之前的边车容器重复, 同一个容器内的重复已单独校验
+	innerPorts, outerPorts := make(map[string]bool), make(map[string]bool)
+	addPorts := func(ports []PortInfo, fldPath *field.Path) {
+		inner, outer := make(map[string]bool), make(map[string]bool)
+		for j, port := range ports {
+			innerKey := fmt.Sprintf("%d/%s", port.InnerPort, port.Protocol)
+			if fldPath != nil && innerPorts[innerKey] {
+				allErrs = append(allErrs, field.Duplicate(fldPath.Index(j).Child("InnerPort"), innerKey))
+			}
+			inner[innerKey] = true
+			if info.HostNetwork || port.OuterPort == 0 {
+				continue
+			}
+			outerKey := fmt.Sprintf("%d/%s", port.OuterPort, port.Protocol)
+			if fldPath != nil && outerPorts[outerKey] {
+				allErrs = append(allErrs, field.Duplicate(fldPath.Index(j).Child("OuterPort"), outerKey))
+			}
+			outer[outerKey] = true
+		}
+		for key := range inner {
+			innerPorts[key] = true
+		}
+		for key := range outer {
+			outerPorts[key] = true
+		}
+	}
+	addPorts(info.Port, nil)
+	for i, spec := range info.Sidecars {
+		addPorts(spec.Port, field.NewPath("Sidecars").Index(i).Child("Port"))
+	}
+	return allErrs
+}
@@ -0,0 +1,200 @@
+package k8s
+
+import (
+	"fmt"
+	logger "github.com/alecthomas/log4go"
+	"strings"
+	"time"
+)
+
+/**
+ *    Description: 节点维护, 禁止调度以及驱逐节点上的app并迁移到其他节点
+ *    Date: 2026/10/19
+ */
+
+const (
+	DrainStageStopped  = "Stopped"  // app已停止
+	DrainStageMigrated = "Migrated" // app已修改目标节点
+	DrainStageStarted  = "Started"  // app已在目标节点重新启动
+	DrainStageDone     = "Done"     // app驱逐完成
+	DrainStageFailed   = "Failed"   // app驱逐失败
+)
+
+type (
+	// DrainOptions 节点驱逐参数
+	DrainOptions struct {
+		TargetNode  string              // 迁移的目标节点, 为空且AutoSelect为false时仅停止app
+		AutoSelect  bool                // TargetNode为空时自动选择目标节点
+		WaitTimeout time.Duration       // 等待容器停止的超时时间, 默认: 2分钟
+		Progress    func(p DrainResult) // app驱逐进度回调
+	}
+	// DrainResult 单个app的驱逐结果
+	DrainResult struct {
+		Name     string
+		FromNode string
+		ToNode   string
+		Stage    string
+		Error    string
+	}
+)
+
+func (manage *ManagerK8s) NodeCordon(name string, isTry bool) error {
+	logger.Info("【节点: %s】cordon node 命令执行中...", name)
+	return manage.api.nodeCordon(name, true, isTry)
+}
+
+func (manage *ManagerK8s) NodeUncordon(name string, isTry bool) error {
+	logger.Info("【节点: %s】uncordon node 命令执行中...", name)
+	return manage.api.nodeCordon(name, false, isTry)
+}
+
+// NodeDrain 禁止节点调度并停止节点上的所有app, 如果指定(或自动选择)了目标节点则迁移到目标节点后按原状态重新启动
+func (manage *ManagerK8s) NodeDrain(name string, opt DrainOptions, isTry bool) ([]DrainResult, error) {
+	logger.Info("【节点: %s】drain node 命令执行中... 目标节点: %s, 自动选择: %v", name, opt.TargetNode, opt.AutoSelect)
+	if opt.WaitTimeout <= 0 {
+		opt.WaitTimeout = 2 * time.Minute
+	}
+	if opt.TargetNode == name {
+		return nil, fmt.Errorf("drain target node[%s] is the draining node", name)
+	}
+	if err := manage.NodeCordon(name, isTry); err != nil {
+		return nil, err
+	}
+	appNames, err := manage.api.listAppsByNode(name)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		results []DrainResult
+		failed  int
+	)
+	for _, appName := range appNames {
+		result := manage.drainApp(appName, name, opt, isTry)
+		if result.Stage == DrainStageFailed {
+			failed++
+		}
+		results = append(results, result)
+	}
+	if failed > 0 {
+		return results, fmt.Errorf("drain node[%s] finished, %d/%d apps failed", name, failed, len(appNames))
+	}
+	return results, nil
+}
+
+// drainApp 驱逐单个app: 停止 -> 修改目标节点 -> 按原状态重新启动
+func (manage *ManagerK8s) drainApp(appName, nodeName string, opt DrainOptions, isTry bool) DrainResult {
+	result := DrainResult{Name: appName, FromNode: nodeName}
+	report := func(stage string, err error) DrainResult {
+		result.Stage = stage
+		if err != nil {
+			result.Stage = DrainStageFailed
+			result.Error = err.Error()
+			logger.Error("【容器: %s】drain 节点: %s 失败, error[%s]", appName, nodeName, err)
+		}
+		if opt.Progress != nil {
+			opt.Progress(result)
+		}
+		return result
+	}
+	// 停止app之前确定目标节点, 目标节点不满足条件时不停止app
+	targetNode := opt.TargetNode
+	if targetNode != "" || opt.AutoSelect {
+		req, err := manage.drainPlacementRequest(appName, nodeName)
+		if err != nil {
+			return report(DrainStageFailed, err)
+		}
+		if targetNode != "" {
+			err = manage.checkDrainTarget(req, targetNode)
+		} else {
+			targetNode, err = manage.selectDrainTarget(req)
+		}
+		if err != nil {
+			return report(DrainStageFailed, err)
+		}
+	}
+	replicas, err := manage.api.statefulSetReplicas(appName, manage.appNamespace)
+	if err != nil {
+		return report(DrainStageFailed, err)
+	}
+	if replicas > 0 {
+		if err = manage.api.statefulSetRunOrStop(appName, manage.appNamespace, "stop", isTry); err != nil {
+			return report(DrainStageFailed, err)
+		}
+		if !isTry {
+			if err = manage.api.waitPodDeleted(appName, manage.appNamespace, opt.WaitTimeout); err != nil {
+				return report(DrainStageFailed, err)
+			}
+		}
+	}
+	report(DrainStageStopped, nil)
+	if targetNode == "" {
+		return report(DrainStageDone, nil)
+	}
+	result.ToNode = targetNode
+	if err = manage.api.statefulSetRetarget(appName, manage.appNamespace, targetNode, isTry); err != nil {
+		return report(DrainStageFailed, err)
+	}
+	report(DrainStageMigrated, nil)
+	if replicas > 0 {
+		if err = manage.api.statefulSetRunOrStop(appName, manage.appNamespace, Action, isTry); err != nil {
+			return report(DrainStageFailed, err)
+		}
+		report(DrainStageStarted, nil)
+	}
+	return report(DrainStageDone, nil)
+}
+
+// drainPlacementRequest 迁移app的调度请求: app占用的主机端口, 污点容忍(pod的配置)以及节点亲和性(StatefulSet的注解)
+func (manage *ManagerK8s) drainPlacementRequest(appName, fromNode string) (placementRequest, error) {
+	statefulSet, err := manage.api.statefulSetGet(appName, manage.appNamespace)
+	if err != nil {
+		return placementRequest{}, err
+	}
+	nodeAffinity, err := savedNodeAffinity(statefulSet.Annotations)
+	if err != nil {
+		return placementRequest{}, err
+	}
+	hostPorts, err := manage.api.listHostPorts()
+	if err != nil {
+		return placementRequest{}, err
+	}
+	req := placementRequest{app: appName, exclude: fromNode, nodeAffinity: nodeAffinity}
+	for _, toleration := range statefulSet.Spec.Template.Spec.Tolerations {
+		req.tolerations = append(req.tolerations, TolerationInfo{Key: toleration.Key, Operator: string(toleration.Operator), Value: toleration.Value, Effect: string(toleration.Effect), Seconds: toleration.TolerationSeconds})
+	}
+	for _, usage := range hostPorts[fromNode] {
+		if usage.app == appName {
+			req.hostPorts = append(req.hostPorts, usage)
+		}
+	}
+	return req, nil
+}
+
+// selectDrainTarget 通过自动调度为app选择迁移的目标节点
+func (manage *ManagerK8s) selectDrainTarget(req placementRequest) (string, error) {
+	decision, err := manage.placeNode(req)
+	if err != nil {
+		return "", err
+	}
+	return decision.NodeName, nil
+}
+
+// checkDrainTarget 指定的目标节点与自动调度使用相同的过滤条件: 节点就绪, 未禁止调度, 污点容忍, 节点亲和性, 主机端口以及资源
+func (manage *ManagerK8s) checkDrainTarget(req placementRequest, targetNode string) error {
+	node, err := manage.GetNode(targetNode)
+	if err != nil {
+		return err
+	}
+	requested, err := manage.api.nodeRequested()
+	if err != nil {
+		return err
+	}
+	hostPorts, err := manage.api.listHostPorts()
+	if err != nil {
+		return err
+	}
+	if candidate := scoreNode(node, requested[targetNode], hostPorts[targetNode], req); !candidate.Eligible {
+		return fmt.Errorf("drain target node[%s] is not eligible: %s", targetNode, strings.Join(candidate.Reasons, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	logger "github.com/alecthomas/log4go"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+/**
+ *    Description: app资源信息的历史记录, 每个app一个固定大小的环形缓冲区, 同一个精度周期内的采样取平均值(降采样)
+ *    Date: 2026/10/19
+ */
+
+const (
+	defaultHistoryRetention  = time.Hour
+	defaultHistoryResolution = 15 * time.Second
+)
+
+type (
+	// StatHistory app在时间范围内的资源信息, cpu单位: 毫核, 内存单位: MB
+	StatHistory struct {
+		Name       string
+		Step       time.Duration
+		Cpu        []SeriesPoint
+		Mem        []SeriesPoint
+		CpuSummary SeriesSummary
+		MemSummary SeriesSummary
+	}
+	// SeriesPoint 一个时间点的资源占用, 为该时间段内采样的平均值
+	SeriesPoint struct {
+		At    time.Time
+		Used  float64
+		Ratio float64 //单位%
+	}
+	// SeriesSummary 时间范围内的资源占用统计, 基于占用值(Used)
+	SeriesSummary struct {
+		Min float64
+		Max float64
+		Avg float64
+		P95 float64
+	}
+	historyPoint struct {
+		at                 time.Time
+		cpuUsed, memUsed   float64
+		cpuRatio, memRatio float64
+		count              int
+	}
+	// statHistory 环形缓冲区, 按时间顺序保存降采样后的资源信息
+	statHistory struct {
+		lock       sync.Mutex
+		resolution time.Duration
+		points     []historyPoint
+		start      int
+		size       int
+	}
+)
+
+func newStatHistory(retention, resolution time.Duration) *statHistory {
+	if retention <= 0 {
+		retention = defaultHistoryRetention
+	}
+	if resolution <= 0 {
+		resolution = defaultHistoryResolution
+	}
+	capacity := int(retention / resolution)
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &statHistory{resolution: resolution, points: make([]historyPoint, capacity)}
+}
+
+// add 添加采样, 与最新的记录在同一个精度周期内时取平均值, 缓冲区已满时覆盖最早的记录
+func (history *statHistory) add(at time.Time, stat StatInfo) {
+	history.lock.Lock()
+	defer history.lock.Unlock()
+	at = at.Truncate(history.resolution)
+	if history.size > 0 {
+		last := &history.points[(history.start+history.size-1)%len(history.points)]
+		if last.at.Equal(at) {
+			n := float64(last.count)
+			last.cpuUsed = (last.cpuUsed*n + float64(stat.CpuLoad.Used)) / (n + 1)
+			last.memUsed = (last.memUsed*n + float64(stat.MemLoad.Used)) / (n + 1)
+			last.cpuRatio = (last.cpuRatio*n + stat.CpuLoad.Ratio) / (n + 1)
+			last.memRatio = (last.memRatio*n + stat.MemLoad.Ratio) / (n + 1)
+			last.count++
+			return
+		}
+		if at.Before(last.at) {
+			return
+		}
+	}
+	point := historyPoint{at: at, cpuUsed: float64(stat.CpuLoad.Used), memUsed: float64(stat.MemLoad.Used), cpuRatio: stat.CpuLoad.Ratio, memRatio: stat.MemLoad.Ratio, count: 1}
+	if history.size < len(history.points) {
+		history.points[(history.start+history.size)%len(history.points)] = point
+		history.size++
+	} else {
+		history.points[history.start] = point
+		history.start = (history.start + 1) % len(history.points)
+	}
+}
+
+// rangeOf 获取 [from, to) 时间范围内的记录
+func (history *statHistory) rangeOf(from, to time.Time) []historyPoint {
+	history.lock.Lock()
+	defer history.lock.Unlock()
+	var points []historyPoint
+	for i := 0; i < history.size; i++ {
+		point := history.points[(history.start+i)%len(history.points)]
+		if !point.at.Before(from) && point.at.Before(to) {
+			points = append(points, point)
+		}
+	}
+	return points
+}
+
+func (cache *ContainerCache) recordHistory(name string, stat StatInfo, at time.Time, opt ClusterOptions) {
+	body, ok := cache.history.Load(name)
+	if !ok {
+		body, _ = cache.history.LoadOrStore(name, newStatHistory(opt.HistoryRetention, opt.HistoryResolution))
+	}
+	body.(*statHistory).add(at, stat)
+}
+
+func (cache *ContainerCache) getHistory(name string) (*statHistory, bool) {
+	if body, ok := cache.history.Load(name); ok {
+		return body.(*statHistory), true
+	}
+	return nil, false
+}
+
+// GetStatHistory 获取app在 [from, to) 时间范围内的资源信息, step 为返回的时间间隔, 小于记录精度时使用记录精度
+func (manage *ManagerK8s) GetStatHistory(name, namespace string, from, to time.Time, step time.Duration) (StatHistory, error) {
+	logger.Info("【容器: %s】 get container stat history 命令执行中... ", name)
+	if !from.Before(to) {
+		return StatHistory{}, errors.New("from must be before to")
+	}
+	history, ok := manage.containerCache.getHistory(name + "_" + namespace)
+	if !ok {
+		return StatHistory{}, fmt.Errorf("no stat history of %s in namespace %s", name, namespace)
+	}
+	if step < history.resolution {
+		step = history.resolution
+	}
+	step = step.Truncate(history.resolution)
+	points := history.rangeOf(from, to)
+	result := StatHistory{Name: name, Step: step}
+	var cpuValues, memValues []float64
+	for i := 0; i < len(points); {
+		// 同一个 step 内的记录取平均值
+		bucket := from.Add(points[i].at.Sub(from) / step * step)
+		var cpu, mem SeriesPoint
+		n := 0
+		for ; i < len(points) && points[i].at.Before(bucket.Add(step)); i++ {
+			cpu.Used += points[i].cpuUsed
+			cpu.Ratio += points[i].cpuRatio
+			mem.Used += points[i].memUsed
+			mem.Ratio += points[i].memRatio
+			cpuValues = append(cpuValues, points[i].cpuUsed)
+			memValues = append(memValues, points[i].memUsed)
+			n++
+		}
+		cpu = SeriesPoint{At: bucket, Used: round2(cpu.Used / float64(n)), Ratio: round2(cpu.Ratio / float64(n))}
+		mem = SeriesPoint{At: bucket, Used: round2(mem.Used / float64(n)), Ratio: round2(mem.Ratio / float64(n))}
+		result.Cpu = append(result.Cpu, cpu)
+		result.Mem = append(result.Mem, mem)
+	}
+	result.CpuSummary = summarize(cpuValues)
+	result.MemSummary = summarize(memValues)
+	return result, nil
+}
+
+// summarize 统计最小值, 最大值, 平均值以及p95(最近秩法)
+func summarize(values []float64) SeriesSummary {
+	if len(values) == 0 {
+		return SeriesSummary{}
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	var sum float64
+	for _, value := range sorted {
+		sum += value
+	}
+	rank := int(math.Ceil(0.95*float64(len(sorted)))) - 1
+	return SeriesSummary{Min: round2(sorted[0]), Max: round2(sorted[len(sorted)-1]), Avg: round2(sum / float64(len(sorted))), P95: round2(sorted[rank])}
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
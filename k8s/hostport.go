@@ -0,0 +1,53 @@
+package k8s
+
+import (
+	"fmt"
+	logger "github.com/alecthomas/log4go"
+)
+
+/**
+ *    Description: 主机端口冲突检测, 创建app前检查目标节点上其他app是否已经占用了相同的主机端口
+ *    Date: 2026/10/19
+ */
+
+// HostPortConflictError 主机端口冲突, Owner 为已经占用端口的app
+type HostPortConflictError struct {
+	Node     string
+	Port     int32
+	Protocol string
+	Owner    string
+}
+
+func (e *HostPortConflictError) Error() string {
+	return fmt.Sprintf("host port %d/%s on node %s is already used by app %s", e.Port, e.Protocol, e.Node, e.Owner)
+}
+
+// hostPortConflicts 检查需要的主机端口与节点上已占用的主机端口是否冲突, 忽略app自身占用的端口
+func hostPortConflicts(want, used []hostPortUsage, app string) []*HostPortConflictError {
+	var conflicts []*HostPortConflictError
+	for _, w := range want {
+		for _, u := range used {
+			if u.app != app && u.port == w.port && u.protocol == w.protocol {
+				conflicts = append(conflicts, &HostPortConflictError{Node: u.node, Port: u.port, Protocol: u.protocol, Owner: u.app})
+			}
+		}
+	}
+	return conflicts
+}
+
+// checkHostPorts 创建app前检查目标节点上的主机端口冲突, 返回第一个冲突的 *HostPortConflictError
+func (manage *ManagerK8s) checkHostPorts(info *CreateReqInfo) error {
+	want := requestedHostPorts(info)
+	if len(want) == 0 {
+		return nil
+	}
+	hostPorts, err := manage.api.listHostPorts()
+	if err != nil {
+		return err
+	}
+	if conflicts := hostPortConflicts(want, hostPorts[info.NodeName], info.Name); len(conflicts) > 0 {
+		logger.Warn("【容器: %s】主机端口冲突: %s", info.Name, conflicts[0])
+		return conflicts[0]
+	}
+	return nil
+}
@@ -7,11 +7,14 @@ import (
 	"github.com/golang/protobuf/proto"
 	v1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/metrics/pkg/client/clientset/versioned"
 	"strconv"
+	"sync"
+	"time"
 )
 
 /**
@@ -23,13 +26,37 @@ type API interface {
 	init(k8sConfig, systemNamespace, appNamespace string) error
 	exit()
 	statefulSetCreate(namespace string, info *ContainerCreateInfo, isTry ...bool) error // 业务app 创建
+	statefulSetUpdate(namespace string, info *ContainerCreateInfo, isTry ...bool) error // 业务app 更新
+	statefulSetNodeName(name, namespace string) (string, error)                         // 业务app 所在节点
+	secretApply(namespace string, secret *corev1.Secret, isTry ...bool) error           // 创建或者更新Secret
+	secretDelete(name, namespace string, isTry ...bool) error                           // 删除Secret
+	statefulSetGet(name, namespace string) (*v1.StatefulSet, error)                     // 获取StatefulSet
+	daemonSetCreate(namespace string, daemonSet *v1.DaemonSet) error                    // 创建DaemonSet
+	daemonSetDelete(name, namespace string) error                                       // 删除DaemonSet
+	listPodsByLabel(namespace, selector string) ([]corev1.Pod, error)                   // 按标签查询pod
 	statefulSetDelete(name, namespace string, isTry ...bool) error                      // 业务app 删除
 	statefulSetRestart(name, namespace string, isTry ...bool) error                     // 容器重启
 	statefulSetRunOrStop(name, namespace, action string, isTry ...bool) error           // 停止或者启动容器
 	watchPodEvents(namespace string)                                                    // 容器运行状态监听
 	containerInfo(name, namespace string) (ContainerInfo, error)                        // 容器信息
 	containerMetricStat(name, namespace string) (StatInfo, error)                       // 容器监控信息
+	listPodUsage(namespace string) ([]podUsage, error)                                  // 空间下所有pod的资源占用
+	setMetricsSource(source string)                                                     // 设置监控数据的来源
+	detectMetricsSource() string                                                        // 检测监控数据的来源
+	nodeCapacity(name string) (nodeCapacity, error)                                     // 节点容量(缓存)
+	podAppName(podName, namespace string) string                                        // pod名称转换为app名称
 	getAppNamesByNamespace(isSystem bool) ([]string, error)                             // 获取所有app名称
+	watchNodeEvents()                                                                   // 节点状态监听
+	listNodes() ([]NodeInfo, error)                                                     // 节点列表
+	getNode(name string) (NodeInfo, error)                                              // 节点信息
+	stoppedAppsByNode() (map[string]int, error)                                         // 节点上停止的app数量
+	nodeCordon(name string, unschedulable bool, isTry ...bool) error                    // 节点禁止/恢复调度
+	listAppsByNode(nodeName string) ([]string, error)                                   // 节点上的app名称
+	statefulSetReplicas(name, namespace string) (int32, error)                          // app 副本数
+	statefulSetRetarget(name, namespace, nodeName string, isTry ...bool) error          // app 迁移目标节点
+	waitPodDeleted(name, namespace string, timeout time.Duration) error                 // 等待容器删除完成
+	listHostPorts() (map[string][]hostPortUsage, error)                                 // app占用的主机端口
+	nodeRequested() (map[string]ResourceInfo, error)                                    // 节点上已申请的资源
 }
 
 type k8sApi struct {
@@ -38,6 +65,12 @@ type k8sApi struct {
 	systemNamespace string
 	appNamespace    string
 	exitCh          chan bool
+	capacity        capacityCache // 节点容量缓存
+	sourceLock      sync.RWMutex
+	metricsSource   string       // 监控数据的来源: metrics-server, kubelet, 通过 setMetricsSource 以及 source 读写
+	summaries       summaryCache // kubelet Summary 缓存, 各空间的采集共用
+	prePullPods     sync.Map     // 预拉取的pod名称, 不属于业务app, 不缓存, 不采集也不通知
+	expectedDeletes sync.Map     // 组件主动删除的app, key: app_空间, value: 删除时间, pod删除事件不发送通知
 }
 
 func (api *k8sApi) init(k8sConfig, systemNamespace, appNamespace string) error {
@@ -62,23 +95,23 @@ func (api *k8sApi) init(k8sConfig, systemNamespace, appNamespace string) error {
 	api.systemNamespace = systemNamespace
 	api.appNamespace = appNamespace
 	api.exitCh = make(chan bool)
+	api.metricsSource = MetricsSourceMetricsServer
 	return nil
 }
 
 func (api *k8sApi) exit() {
 	close(api.exitCh)
 }
-func (api *k8sApi) statefulSetCreate(namespace string, info *ContainerCreateInfo, isTry ...bool) error {
-	if info == nil {
-		return fmt.Errorf("ContainerCreateInfo nil")
-	}
-	var err error
+
+// newStatefulSet 根据创建信息生成StatefulSet
+func newStatefulSet(namespace string, info *ContainerCreateInfo) *v1.StatefulSet {
 	// 定义: StatefulSet
 	statefulSet := &v1.StatefulSet{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      info.Name,
-			Namespace: namespace,
-			Labels:    info.Label, // 核心: map[string]string{"node_ip": info.NodeName, "app": info.Name}
+			Name:        info.Name,
+			Namespace:   namespace,
+			Labels:      info.Label, // 核心: map[string]string{"node_ip": info.NodeName, "app": info.Name}
+			Annotations: info.AppAnnotations,
 		},
 		Spec: v1.StatefulSetSpec{
 			Replicas:    proto.Int32(0),
@@ -90,27 +123,25 @@ func (api *k8sApi) statefulSetCreate(namespace string, info *ContainerCreateInfo
 			},
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{
-						"app": fmt.Sprintf("%s-%s", info.NodeName, info.Name),
-					},
+					Labels:      podTemplateLabels(info),
+					Annotations: info.Annotations,
 				},
 				Spec: corev1.PodSpec{
 					HostNetwork: info.HostNetwork,
 					NodeSelector: map[string]string{
-						"kubernetes.io/hostname": info.NodeName,
+						labelHostname: info.NodeName,
 					},
 					Volumes:       info.Volumes,
 					RestartPolicy: corev1.RestartPolicy(info.Restart), // statefulSet仅仅支持: Always
 					Containers: []corev1.Container{
 						{
-							Name:         info.Name, // 容器名称,设置唯一可以和StatefulSet名称设置一个,因为我们设计都是按照单个pod启动,方便我们进行查看
-							Image:        info.Image,
-							Ports:        info.Port,
-							Env:          info.Env, // 添加环境变量
-							VolumeMounts: info.VolumeMounts,
-							SecurityContext: &corev1.SecurityContext{
-								Privileged: proto.Bool(info.Privileged), // 是否使用特权模式, 有的APP需要使用
-							},
+							Name:            info.Name, // 容器名称,设置唯一可以和StatefulSet名称设置一个,因为我们设计都是按照单个pod启动,方便我们进行查看
+							Image:           info.Image,
+							Ports:           info.Port,
+							Env:             info.Env, // 添加环境变量
+							VolumeMounts:    info.VolumeMounts,
+							Resources:       info.Resources,
+							SecurityContext: containerSecurityContext(info),
 						},
 					},
 				},
@@ -118,6 +149,53 @@ func (api *k8sApi) statefulSetCreate(namespace string, info *ContainerCreateInfo
 		},
 	}
 
+	// init容器以及边车容器使用主容器的限制(运行用户, 只读根文件系统, seccomp, 删除的能力), 不继承特权模式以及添加的能力
+	for _, container := range info.InitContainers {
+		container.SecurityContext = extraSecurityContext(info)
+		statefulSet.Spec.Template.Spec.InitContainers = append(statefulSet.Spec.Template.Spec.InitContainers, container)
+	}
+	for _, container := range info.Sidecars {
+		container.SecurityContext = extraSecurityContext(info)
+		statefulSet.Spec.Template.Spec.Containers = append(statefulSet.Spec.Template.Spec.Containers, container)
+	}
+	container := &statefulSet.Spec.Template.Spec.Containers[0]
+	if info.FsGroup != nil {
+		statefulSet.Spec.Template.Spec.SecurityContext = &corev1.PodSecurityContext{FSGroup: info.FsGroup}
+	}
+	container.Command = info.Command
+	container.Args = info.Args
+	container.WorkingDir = info.WorkingDir
+	if info.PostStart != nil || info.PreStop != nil {
+		container.Lifecycle = &corev1.Lifecycle{PostStart: info.PostStart, PreStop: info.PreStop}
+	}
+	statefulSet.Spec.Template.Spec.TerminationGracePeriodSeconds = info.GracePeriod
+	statefulSet.Spec.Template.Spec.Tolerations = info.Tolerations
+	statefulSet.Spec.Template.Spec.PriorityClassName = info.PriorityClassName
+	statefulSet.Spec.Template.Spec.DNSPolicy = info.DNSPolicy
+	statefulSet.Spec.Template.Spec.DNSConfig = info.DNSConfig
+	statefulSet.Spec.Template.Spec.HostAliases = info.HostAliases
+	statefulSet.Spec.Template.Spec.ImagePullSecrets = info.ImagePullSecrets
+	container.ImagePullPolicy = info.ImagePullPolicy
+	return statefulSet
+}
+
+// podTemplateLabels pod的标签: app的标签(用于按标签查询)以及pod选择标签 app: 节点名称-app名称,
+// 用户标签不能使用保留的 app 以及 nodeIP(CreateReqInfo.Validate 校验), 否则会被pod选择标签覆盖
+func podTemplateLabels(info *ContainerCreateInfo) map[string]string {
+	labels := make(map[string]string, len(info.Label)+1)
+	for key, value := range info.Label {
+		labels[key] = value
+	}
+	labels[labelApp] = fmt.Sprintf("%s-%s", info.NodeName, info.Name)
+	return labels
+}
+
+func (api *k8sApi) statefulSetCreate(namespace string, info *ContainerCreateInfo, isTry ...bool) error {
+	if info == nil {
+		return fmt.Errorf("ContainerCreateInfo nil")
+	}
+	var err error
+	statefulSet := newStatefulSet(namespace, info)
 	if len(isTry) > 0 && isTry[0] {
 		_, err = api.client.AppsV1().StatefulSets(namespace).Create(context.Background(), statefulSet, metav1.CreateOptions{DryRun: []string{"All"}})
 	} else {
@@ -126,11 +204,54 @@ func (api *k8sApi) statefulSetCreate(namespace string, info *ContainerCreateInfo
 	return err
 }
 
+// statefulSetUpdate 更新StatefulSet, selector 以及 serviceName 不可变更, 保留原有的副本数以及pod选择标签
+func (api *k8sApi) statefulSetUpdate(namespace string, info *ContainerCreateInfo, isTry ...bool) error {
+	if info == nil {
+		return fmt.Errorf("ContainerCreateInfo nil")
+	}
+	statefulSet, err := api.client.AppsV1().StatefulSets(namespace).Get(context.TODO(), info.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	newSet := newStatefulSet(namespace, info)
+	// pod选择标签不可变更, 使用原有的值
+	newSet.Spec.Template.Labels[labelApp] = statefulSet.Spec.Template.Labels[labelApp]
+	statefulSet.Labels = newSet.Labels
+	// 只更新组件管理的注解, 保留其他工具添加的注解
+	if statefulSet.Annotations == nil {
+		statefulSet.Annotations = make(map[string]string)
+	}
+	delete(statefulSet.Annotations, annotationNodeAffinity)
+	for key, value := range newSet.Annotations {
+		statefulSet.Annotations[key] = value
+	}
+	statefulSet.Spec.Template = newSet.Spec.Template
+	if len(isTry) > 0 && isTry[0] {
+		_, err = api.client.AppsV1().StatefulSets(namespace).Update(context.Background(), statefulSet, metav1.UpdateOptions{DryRun: []string{"All"}})
+	} else {
+		_, err = api.client.AppsV1().StatefulSets(namespace).Update(context.Background(), statefulSet, metav1.UpdateOptions{})
+	}
+	return err
+}
+
+// statefulSetNodeName 获取app固定的节点名称
+func (api *k8sApi) statefulSetNodeName(name, namespace string) (string, error) {
+	statefulSet, err := api.client.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	if nodeName := statefulSet.Spec.Template.Spec.NodeSelector[labelHostname]; nodeName != "" {
+		return nodeName, nil
+	}
+	return statefulSet.Labels[labelNodeIP], nil
+}
+
 func (api *k8sApi) statefulSetDelete(name, namespace string, isTry ...bool) error {
 	if len(isTry) > 0 && isTry[0] {
 		return api.client.AppsV1().StatefulSets(namespace).Delete(context.Background(), name, metav1.DeleteOptions{DryRun: []string{"All"}})
 	} else {
-		return api.client.AppsV1().StatefulSets(namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
+		api.expectDeletion(name, namespace)
+		return api.cancelDeletion(name, namespace, api.client.AppsV1().StatefulSets(namespace).Delete(context.Background(), name, metav1.DeleteOptions{}))
 	}
 }
 
@@ -139,6 +260,7 @@ func (api *k8sApi) statefulSetRunOrStop(name, namespace, action string, isTry ..
 	if err != nil {
 		return err
 	}
+	running := statefulSet.Spec.Replicas == nil || *statefulSet.Spec.Replicas > 0
 	if action == Action {
 		statefulSet.Spec.Replicas = proto.Int32(1)
 	} else {
@@ -147,7 +269,14 @@ func (api *k8sApi) statefulSetRunOrStop(name, namespace, action string, isTry ..
 	if len(isTry) > 0 && isTry[0] {
 		_, err = api.client.AppsV1().StatefulSets(namespace).Update(context.Background(), statefulSet, metav1.UpdateOptions{DryRun: []string{"All"}})
 	} else {
+		// 停止运行中的app会删除pod
+		if action != Action && running {
+			api.expectDeletion(name, namespace)
+		}
 		_, err = api.client.AppsV1().StatefulSets(namespace).Update(context.Background(), statefulSet, metav1.UpdateOptions{})
+		if action != Action && running {
+			err = api.cancelDeletion(name, namespace, err)
+		}
 	}
 	return err
 }
@@ -156,7 +285,59 @@ func (api *k8sApi) statefulSetRestart(name, namespace string, isTry ...bool) err
 	if len(isTry) > 0 && isTry[0] {
 		return api.client.CoreV1().Pods(namespace).Delete(context.Background(), podName, metav1.DeleteOptions{DryRun: []string{"All"}})
 	} else {
-		return api.client.CoreV1().Pods(namespace).Delete(context.Background(), podName, metav1.DeleteOptions{})
+		api.expectDeletion(name, namespace)
+		return api.cancelDeletion(name, namespace, api.client.CoreV1().Pods(namespace).Delete(context.Background(), podName, metav1.DeleteOptions{}))
+	}
+}
+
+func (api *k8sApi) statefulSetReplicas(name, namespace string) (int32, error) {
+	statefulSet, err := api.client.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return 0, err
+	}
+	if statefulSet.Spec.Replicas == nil {
+		return 0, nil
+	}
+	return *statefulSet.Spec.Replicas, nil
+}
+
+// statefulSetRetarget 修改app固定的目标节点, selector 不可变更, 仅修改节点选择器以及节点标签
+func (api *k8sApi) statefulSetRetarget(name, namespace, nodeName string, isTry ...bool) error {
+	statefulSet, err := api.client.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if statefulSet.Labels == nil {
+		statefulSet.Labels = make(map[string]string)
+	}
+	statefulSet.Labels[labelNodeIP] = nodeName
+	if statefulSet.Spec.Template.Spec.NodeSelector == nil {
+		statefulSet.Spec.Template.Spec.NodeSelector = make(map[string]string)
+	}
+	statefulSet.Spec.Template.Spec.NodeSelector[labelHostname] = nodeName
+	if len(isTry) > 0 && isTry[0] {
+		_, err = api.client.AppsV1().StatefulSets(namespace).Update(context.Background(), statefulSet, metav1.UpdateOptions{DryRun: []string{"All"}})
+	} else {
+		_, err = api.client.AppsV1().StatefulSets(namespace).Update(context.Background(), statefulSet, metav1.UpdateOptions{})
+	}
+	return err
+}
+
+// waitPodDeleted 等待app的容器删除完成
+func (api *k8sApi) waitPodDeleted(name, namespace string, timeout time.Duration) error {
+	podName := fmt.Sprintf("%s-0", name)
+	deadline := time.Now().Add(timeout)
+	for {
+		if _, err := api.client.CoreV1().Pods(namespace).Get(context.TODO(), podName, metav1.GetOptions{}); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			return err
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("wait pod[%s] deleted timeout", podName)
+		}
+		time.Sleep(2 * time.Second)
 	}
 }
 
@@ -172,23 +353,57 @@ func (api *k8sApi) containerInfo(name, namespace string) (ContainerInfo, error)
 	if pod, err := api.client.CoreV1().Pods(namespace).Get(context.TODO(), podName, metav1.GetOptions{}); err != nil {
 		return ContainerInfo{}, err
 	} else {
-		return ContainerInfo{
-			Name:         name,
-			HostIP:       pod.Status.HostIP,
-			PodIP:        pod.Status.PodIP,
-			Status:       string(pod.Status.Phase),
-			ReStartCount: int(pod.Status.ContainerStatuses[0].RestartCount),
-			NewStartAt:   pod.Status.ContainerStatuses[0].State.Running.StartedAt.Time,
-		}, nil
+		return podToContainerInfo(name, pod), nil
 	}
 }
 
+// podToContainerInfo 转换pod的状态信息, 包含每个容器(含init容器)的状态, 重启次数为所有容器之和
+func podToContainerInfo(name string, pod *corev1.Pod) ContainerInfo {
+	info := ContainerInfo{
+		Name:     name,
+		NodeName: pod.Spec.NodeName,
+		HostIP:   pod.Status.HostIP,
+		PodIP:    pod.Status.PodIP,
+		Status:   string(pod.Status.Phase),
+		Labels:   pod.Labels,
+	}
+	appendStatus := func(status corev1.ContainerStatus, isInit bool) {
+		state := ContainerStateInfo{Name: status.Name, Image: status.Image, Init: isInit, Ready: status.Ready, ReStartCount: int(status.RestartCount)}
+		switch {
+		case status.State.Running != nil:
+			state.State = "Running"
+			state.StartAt = status.State.Running.StartedAt.Time
+		case status.State.Waiting != nil:
+			state.State = "Waiting"
+			state.Reason = status.State.Waiting.Reason
+		case status.State.Terminated != nil:
+			state.State = "Terminated"
+			state.Reason = status.State.Terminated.Reason
+			state.StartAt = status.State.Terminated.StartedAt.Time
+		}
+		if status.LastTerminationState.Terminated != nil {
+			state.LastReason = status.LastTerminationState.Terminated.Reason
+		}
+		info.ReStartCount += state.ReStartCount
+		// 主容器与app同名, 其启动时间作为app的启动时间
+		if !isInit && (status.Name == name || info.NewStartAt.IsZero()) && !state.StartAt.IsZero() {
+			info.NewStartAt = state.StartAt
+		}
+		info.Containers = append(info.Containers, state)
+	}
+	for _, status := range pod.Status.InitContainerStatuses {
+		appendStatus(status, true)
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		appendStatus(status, false)
+	}
+	return info
+}
+
 func (api *k8sApi) containerMetricStat(name, namespace string) (StatInfo, error) {
 	var (
-		isSys       bool
-		totalCPUNum uint64
-		totalMemNum uint64
-		podName     string
+		isSys   bool
+		podName string
 	)
 	if namespace == api.systemNamespace {
 		isSys = true
@@ -203,36 +418,68 @@ func (api *k8sApi) containerMetricStat(name, namespace string) (StatInfo, error)
 	if err != nil {
 		return StatInfo{}, err
 	}
-	if nodeInfo, err := api.client.CoreV1().Nodes().Get(context.TODO(), containerInfo.HostIP, metav1.GetOptions{}); err != nil {
+	capacity, err := api.nodeCapacity(containerInfo.HostIP)
+	if err != nil {
 		return StatInfo{}, err
-	} else {
-		totalCPU := nodeInfo.Status.Capacity[corev1.ResourceCPU]
-		totalMemory := nodeInfo.Status.Capacity[corev1.ResourceMemory]
-		// 转换为数字值
-		totalCPUNum = uint64(totalCPU.MilliValue())
-		totalMemNum = uint64(totalMemory.Value())
+	}
+	if api.source() == MetricsSourceKubelet {
+		usage, err := api.kubeletPodUsage(capacity.name, podName, namespace)
+		if err != nil {
+			return StatInfo{}, err
+		}
+		return podUsageToStatInfo(name, usage, capacity), nil
 	}
 	if podMetric, err := api.metric.MetricsV1beta1().PodMetricses(namespace).Get(context.TODO(), podName, metav1.GetOptions{}); err != nil {
 		return StatInfo{}, err
 	} else {
-		resourceCPU := podMetric.Containers[0].Usage[corev1.ResourceCPU]
-		resourceMemory := podMetric.Containers[0].Usage[corev1.ResourceMemory]
-		podUseCPURatio, _ := strconv.ParseFloat(fmt.Sprintf("%0.2f", float64(resourceCPU.MilliValue())/float64(totalCPUNum)*100), 64)
-		podUseMemoryRatio, _ := strconv.ParseFloat(fmt.Sprintf("%0.2f", float64(resourceMemory.Value())/float64(totalMemNum)*100), 64)
-		return StatInfo{
-			Name: name,
-			CpuLoad: LoadInfo{
-				Total: totalCPUNum,
-				Used:  uint64(resourceCPU.MilliValue()),
-				Ratio: podUseCPURatio,
-			},
-			MemLoad: LoadInfo{
-				Total: totalMemNum / 1024 / 1024,
-				Used:  uint64(resourceMemory.Value() / 1024 / 1024),
-				Ratio: podUseMemoryRatio,
-			},
-		}, nil
+		return podUsageToStatInfo(name, podMetricToUsage(*podMetric), capacity), nil
+	}
+}
+
+// podUsageToStatInfo 转换pod的资源占用, app的资源占用为所有容器之和
+func podUsageToStatInfo(name string, usage podUsage, capacity nodeCapacity) StatInfo {
+	var sumCPU, sumMemory uint64
+	stat := StatInfo{Name: name}
+	for _, container := range usage.containers {
+		sumCPU += container.cpu
+		sumMemory += container.memory
+		stat.Containers = append(stat.Containers, ContainerStat{
+			Name:       container.name,
+			CpuLoad:    cpuLoad(container.cpu, capacity.cpu),
+			MemLoad:    memLoad(container.memory, capacity.memory),
+			RootfsUsed: container.rootfs / 1024 / 1024,
+		})
+		stat.DiskLoad.RootfsUsed += container.rootfs
+	}
+	stat.CpuLoad = cpuLoad(sumCPU, capacity.cpu)
+	stat.MemLoad = memLoad(sumMemory, capacity.memory)
+	stat.DiskLoad.RootfsUsed /= 1024 * 1024
+	if usage.network != nil {
+		stat.NetLoad = NetInfo{RxBytes: usage.network.rx, TxBytes: usage.network.tx, At: usage.network.at}
+	}
+	for _, volume := range usage.volumes {
+		stat.DiskLoad.VolumeUsed += volume.used
+		stat.DiskLoad.Volumes = append(stat.DiskLoad.Volumes, VolumeUsage{Name: volume.name, Used: volume.used / 1024 / 1024, Capacity: volume.capacity / 1024 / 1024})
+	}
+	stat.DiskLoad.VolumeUsed /= 1024 * 1024
+	return stat
+}
+
+func cpuLoad(used, total uint64) LoadInfo {
+	return LoadInfo{Total: total, Used: used, Ratio: loadRatio(used, total)}
+}
+
+// memLoad 内存转换为MB
+func memLoad(used, total uint64) LoadInfo {
+	return LoadInfo{Total: total / 1024 / 1024, Used: used / 1024 / 1024, Ratio: loadRatio(used, total)}
+}
+
+func loadRatio(used, total uint64) float64 {
+	if total == 0 {
+		return 0
 	}
+	ratio, _ := strconv.ParseFloat(fmt.Sprintf("%0.2f", float64(used)/float64(total)*100), 64)
+	return ratio
 }
 
 func (api *k8sApi) getAppNamesByNamespace(isSystem bool) ([]string, error) {
@@ -243,7 +490,7 @@ func (api *k8sApi) getAppNamesByNamespace(isSystem bool) ([]string, error) {
 	if isSystem {
 		namespace = api.systemNamespace
 	}
-	if pods, err := api.client.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{}); err != nil {
+	if pods, err := api.client.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{LabelSelector: excludePrePull}); err != nil {
 		return nil, err
 	} else {
 		for _, item := range pods.Items {
@@ -0,0 +1,224 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	logger "github.com/alecthomas/log4go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sync"
+	"time"
+)
+
+/**
+ *    Description: 通过 API server 的节点代理读取 kubelet 的 /stats/summary, 在没有部署 metrics-server 的集群中采集资源信息
+ *    Date: 2026/10/19
+ */
+
+const (
+	MetricsSourceMetricsServer = "metrics-server"
+	MetricsSourceKubelet       = "kubelet"
+	metricsGroupName           = "metrics.k8s.io"
+	summaryCacheTTL            = time.Second // 不超过最小采集周期, 同一次采集的各空间共用节点的 Summary
+)
+
+type (
+	// summaryCache 所有节点的 kubelet Summary, 系统空间以及业务空间同时采集时只请求一次
+	summaryCache struct {
+		lock      sync.Mutex
+		at        time.Time
+		summaries []*kubeletSummary
+		err       error
+	}
+	// kubeletSummary kubelet Summary API 的返回值, 仅包含使用的字段, 参考: k8s.io/kubelet/pkg/apis/stats/v1alpha1
+	kubeletSummary struct {
+		Node struct {
+			NodeName string `json:"nodeName"`
+		} `json:"node"`
+		Pods []kubeletPodStats `json:"pods"`
+	}
+	kubeletPodStats struct {
+		PodRef struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"podRef"`
+		Containers []kubeletContainerStats `json:"containers"`
+		Network    *struct {
+			Time    metav1.Time `json:"time"`
+			RxBytes *uint64     `json:"rxBytes"`
+			TxBytes *uint64     `json:"txBytes"`
+		} `json:"network"`
+		VolumeStats []struct {
+			Name          string  `json:"name"`
+			UsedBytes     *uint64 `json:"usedBytes"`
+			CapacityBytes *uint64 `json:"capacityBytes"`
+		} `json:"volume"`
+	}
+	kubeletContainerStats struct {
+		Name string `json:"name"`
+		CPU  *struct {
+			Time           metav1.Time `json:"time"`
+			UsageNanoCores *uint64     `json:"usageNanoCores"`
+		} `json:"cpu"`
+		Memory *struct {
+			WorkingSetBytes *uint64 `json:"workingSetBytes"`
+		} `json:"memory"`
+		Rootfs *struct {
+			UsedBytes *uint64 `json:"usedBytes"`
+		} `json:"rootfs"`
+	}
+)
+
+// detectMetricsSource 检测集群是否部署了 metrics-server(metrics.k8s.io), 未部署时使用 kubelet
+func (api *k8sApi) detectMetricsSource() string {
+	groups, err := api.client.Discovery().ServerGroups()
+	if err != nil {
+		logger.Warn("检测 metrics.k8s.io 失败: %v, 使用 metrics-server", err)
+		return MetricsSourceMetricsServer
+	}
+	for _, group := range groups.Groups {
+		if group.Name == metricsGroupName {
+			return MetricsSourceMetricsServer
+		}
+	}
+	return MetricsSourceKubelet
+}
+
+func (api *k8sApi) setMetricsSource(source string) {
+	api.sourceLock.Lock()
+	defer api.sourceLock.Unlock()
+	api.metricsSource = source
+}
+
+func (api *k8sApi) source() string {
+	api.sourceLock.RLock()
+	defer api.sourceLock.RUnlock()
+	return api.metricsSource
+}
+
+// nodeSummary 通过节点代理获取 kubelet 的资源信息
+func (api *k8sApi) nodeSummary(node string) (*kubeletSummary, error) {
+	data, err := api.client.CoreV1().RESTClient().Get().Resource("nodes").Name(node).SubResource("proxy").Suffix("stats/summary").DoRaw(context.TODO())
+	if err != nil {
+		internalMetrics.apiError("kubelet_summary")
+		return nil, err
+	}
+	summary := &kubeletSummary{}
+	if err = json.Unmarshal(data, summary); err != nil {
+		return nil, err
+	}
+	if summary.Node.NodeName == "" {
+		summary.Node.NodeName = node
+	}
+	return summary, nil
+}
+
+// listKubeletPodUsage 返回空间下所有pod的资源占用, 所有节点的 Summary 在 summaryCacheTTL 内各空间共用
+func (api *k8sApi) listKubeletPodUsage(namespace string) ([]podUsage, error) {
+	summaries, err := api.nodeSummaries()
+	if err != nil {
+		return nil, err
+	}
+	var usages []podUsage
+	for _, summary := range summaries {
+		for _, pod := range summary.Pods {
+			if pod.PodRef.Namespace == namespace && !api.isPrePullPod(pod.PodRef.Name) {
+				usages = append(usages, summaryPodUsage(summary.Node.NodeName, pod))
+			}
+		}
+	}
+	return usages, nil
+}
+
+// nodeSummaries 并发获取所有节点的 Summary, 缓存未过期时直接返回; 获取期间持有锁, 同时采集的其他空间等待并共用结果;
+// 部分节点失败时忽略该节点
+func (api *k8sApi) nodeSummaries() ([]*kubeletSummary, error) {
+	api.summaries.lock.Lock()
+	defer api.summaries.lock.Unlock()
+	if time.Since(api.summaries.at) < summaryCacheTTL {
+		return api.summaries.summaries, api.summaries.err
+	}
+	nodes, err := api.nodeNames()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		lock      sync.Mutex
+		wg        sync.WaitGroup
+		summaries []*kubeletSummary
+		lastErr   error
+		failed    int
+	)
+	for _, node := range nodes {
+		wg.Add(1)
+		go func(node string) {
+			defer wg.Done()
+			summary, err := api.nodeSummary(node)
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				failed, lastErr = failed+1, err
+				logger.Info("【节点: %s】获取 kubelet 资源信息异常: %v", node, err)
+				return
+			}
+			summaries = append(summaries, summary)
+		}(node)
+	}
+	wg.Wait()
+	if len(nodes) > 0 && failed == len(nodes) {
+		summaries, err = nil, fmt.Errorf("get kubelet summary of all nodes failed: %v", lastErr)
+	}
+	api.summaries.at, api.summaries.summaries, api.summaries.err = time.Now(), summaries, err
+	return summaries, err
+}
+
+// kubeletPodUsage 获取单个pod的资源占用
+func (api *k8sApi) kubeletPodUsage(node, podName, namespace string) (podUsage, error) {
+	summary, err := api.nodeSummary(node)
+	if err != nil {
+		return podUsage{}, err
+	}
+	for _, pod := range summary.Pods {
+		if pod.PodRef.Name == podName && pod.PodRef.Namespace == namespace {
+			return summaryPodUsage(summary.Node.NodeName, pod), nil
+		}
+	}
+	return podUsage{}, fmt.Errorf("pod %s/%s not found in kubelet summary of node %s", namespace, podName, node)
+}
+
+func uint64Value(value *uint64) uint64 {
+	if value == nil {
+		return 0
+	}
+	return *value
+}
+
+// summaryPodUsage 转换 kubelet 的pod资源信息, cpu 由纳核转换为毫核, 内存使用 workingSet(与 metrics-server 一致)
+func summaryPodUsage(node string, pod kubeletPodStats) podUsage {
+	usage := podUsage{name: pod.PodRef.Name, node: node}
+	for _, container := range pod.Containers {
+		stat := containerUsage{name: container.Name}
+		if container.CPU != nil {
+			if container.CPU.UsageNanoCores != nil {
+				stat.cpu = *container.CPU.UsageNanoCores / 1000000
+			}
+			if container.CPU.Time.After(usage.timestamp) {
+				usage.timestamp = container.CPU.Time.Time
+			}
+		}
+		if container.Memory != nil && container.Memory.WorkingSetBytes != nil {
+			stat.memory = *container.Memory.WorkingSetBytes
+		}
+		if container.Rootfs != nil {
+			stat.rootfs = uint64Value(container.Rootfs.UsedBytes)
+		}
+		usage.containers = append(usage.containers, stat)
+	}
+	if pod.Network != nil {
+		usage.network = &networkUsage{at: pod.Network.Time.Time, rx: uint64Value(pod.Network.RxBytes), tx: uint64Value(pod.Network.TxBytes)}
+	}
+	for _, volume := range pod.VolumeStats {
+		usage.volumes = append(usage.volumes, volumeUsage{name: volume.Name, used: uint64Value(volume.UsedBytes), capacity: uint64Value(volume.CapacityBytes)})
+	}
+	return usage
+}
@@ -0,0 +1,87 @@
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"strings"
+)
+
+/**
+ *    Description: 容器启动命令以及生命周期钩子: postStart, preStop 以及优雅停止时间
+ *    Date: 2026/10/19
+ */
+
+// lifecycleHandler 转换为k8s的钩子配置
+func lifecycleHandler(hook *HookInfo) *corev1.LifecycleHandler {
+	if hook == nil {
+		return nil
+	}
+	if len(hook.Command) > 0 {
+		return &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: hook.Command}}
+	}
+	return &corev1.LifecycleHandler{HTTPGet: &corev1.HTTPGetAction{Path: hook.HttpPath, Port: intstr.FromInt(int(hook.HttpPort))}}
+}
+
+// mergePostStart ULIMIT 生成的 postStart 命令与请求的 postStart 钩子只能二选一, 合并需要依赖镜像中的shell, 校验时已拒绝
+func mergePostStart(ulimit []string, hook *HookInfo) (*corev1.LifecycleHandler, error) {
+	if len(ulimit) == 0 {
+		return lifecycleHandler(hook), nil
+	}
+	if hook != nil {
+		return nil, errors.New("ULIMIT can not be combined with a postStart hook")
+	}
+	return &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: ulimit}}, nil
+}
+
+func validateHook(hook *HookInfo, fldPath *field.Path) field.ErrorList {
+	if hook == nil {
+		return nil
+	}
+	var allErrs field.ErrorList
+	if len(hook.Command) > 0 && hook.HttpPort > 0 {
+		return append(allErrs, field.Invalid(fldPath, "", "only one of Command or HttpPort may be set"))
+	}
+	if len(hook.Command) == 0 && hook.HttpPort == 0 {
+		return append(allErrs, field.Required(fldPath, "one of Command or HttpPort must be set"))
+	}
+	if hook.HttpPort > 0 && hook.HttpPath != "" && !strings.HasPrefix(hook.HttpPath, "/") {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("HttpPath"), hook.HttpPath, "must start with '/'"))
+	}
+	return allErrs
+}
+
+func validateLifecycle(info *CreateReqInfo) field.ErrorList {
+	var allErrs field.ErrorList
+	if info.WorkingDir != "" {
+		allErrs = append(allErrs, validateAbsPath(info.WorkingDir, field.NewPath("WorkingDir"))...)
+	}
+	allErrs = append(allErrs, validateHook(info.PostStart, field.NewPath("PostStart"))...)
+	// ULIMIT 通过 postStart 钩子执行 prlimit 实现, 与请求的钩子合并需要依赖镜像中的shell
+	if info.PostStart != nil {
+		for _, env := range info.Env {
+			if env.Key == ENV_ULIMIT_NAME {
+				allErrs = append(allErrs, field.Forbidden(field.NewPath("PostStart"), "can not be combined with ULIMIT, which uses the postStart hook to run prlimit"))
+				break
+			}
+		}
+	}
+	allErrs = append(allErrs, validateHook(info.PreStop, field.NewPath("PreStop"))...)
+	if info.TerminationGracePeriod != nil && *info.TerminationGracePeriod < 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("TerminationGracePeriod"), *info.TerminationGracePeriod, "must be greater than or equal to 0"))
+	}
+	return allErrs
+}
+
+// hookDescription 钩子的描述, 用于日志
+func hookDescription(hook *HookInfo) string {
+	if hook == nil {
+		return ""
+	}
+	if len(hook.Command) > 0 {
+		return strings.Join(hook.Command, " ")
+	}
+	return fmt.Sprintf("GET :%d%s", hook.HttpPort, hook.HttpPath)
+}
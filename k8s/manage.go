@@ -1,9 +1,12 @@
 package k8s
 
 import (
+	"fmt"
 	logger "github.com/alecthomas/log4go"
 	"github.com/golang/protobuf/proto"
 	corev1 "k8s.io/api/core/v1"
+	"net/http"
+	"time"
 )
 
 /**
@@ -19,6 +22,30 @@ type ManagerAPI interface {
 	Init(conf, systemNamespace, appNamespace string) error
 	Start()
 	StatefulSetCreate(info *CreateReqInfo, isTry bool) error
+	StatefulSetCreateAuto(info *CreateReqInfo, isTry bool) (PlacementDecision, error)
+	StatefulSetUpdate(info *CreateReqInfo, isTry bool) error
+	StatefulSetUpdatePrePull(info *CreateReqInfo, opt PrePullOptions, isTry bool) ([]PrePullResult, error)
+	ImagePrePull(images []string, opt PrePullOptions) ([]PrePullResult, error)
+	AppSpecApply(data []byte, isTry bool) ([]AppSpecResult, error)
+	AppSpecExport(name string) (*AppSpec, error)
+	SelectNode(info *CreateReqInfo) (PlacementDecision, error)
+	SetClusterOptions(opt ClusterOptions) error
+	SetPolicies(policies ...Policy) error
+	SetAlertRules(rules ...AlertRule) error
+	SetNotifiers(notifiers ...Notifier)
+	AddSilence(silence Silence) (string, error)
+	DeleteSilence(id string)
+	ListSilences() []Silence
+	ActiveAlerts() []Alert
+	NotifyEvent(notification Notification)
+	SetMonitorOptions(namespace string, opt MonitorOptions) error
+	GetMonitorOptions(namespace string) MonitorOptions
+	MonitorStart(name, namespace string)
+	MonitorPause(name, namespace string)
+	MonitorStop(name, namespace string)
+	MonitorStatus(name, namespace string) string
+	RegistryCredentialApply(cred *RegistryCredential, isTry bool) error
+	RegistryCredentialDelete(name, namespace string, isTry bool) error
 	StatefulSetDelete(name string, isTry bool) error
 	StatefulSetRunOrStop(name, action string, isTry bool) error
 	StatefulSetRestart(name string, isTry bool) error
@@ -30,10 +57,23 @@ type ManagerAPI interface {
 	SetCacheStatInfo(name string, namespace string, info StatInfo)
 	DelCacheContainerMonitor(name string, namespace string)
 	DelContainerStatInfo(name string, namespace string)
+	QueryStats(query StatQuery) (StatQueryResult, error)
+	AggregateStats(by, labelKey string, namespaces ...string) ([]StatGroup, error)
 	GetAllStatInfoOfSortByCpu(desc bool, namespace string) []*StatInfo
+	MetricsHandler() http.Handler
+	GetStatHistory(name, namespace string, from, to time.Time, step time.Duration) (StatHistory, error)
 	GetAllStatInfoOfSortByMem(desc bool, namespace string) []*StatInfo
+	GetAllStatInfoOfSortByNet(desc bool, namespace string) []*StatInfo
+	GetAllStatInfoOfSortByDisk(desc bool, namespace string) []*StatInfo
 	InitStatByNamespace(appNames []string, isSys bool)
 	GetAppNamesByNamespace(isSystem bool) ([]string, error)
+	ListNodes() ([]NodeInfo, error)
+	GetNode(name string) (NodeInfo, error)
+	SetCacheNodeInfo(info NodeInfo)
+	DelCacheNodeInfo(name string)
+	NodeCordon(name string, isTry bool) error
+	NodeUncordon(name string, isTry bool) error
+	NodeDrain(name string, opt DrainOptions, isTry bool) ([]DrainResult, error)
 	Stop()
 }
 
@@ -44,10 +84,15 @@ type ManagerK8s struct {
 	appNamespace    string
 	eventExitCh     chan bool
 	containerCache  *ContainerCache
+	nodeCache       *NodeCache
+	options         ClusterOptions
+	policies        []Policy
+	alerts          *alertEngine
+	monitors        *monitorControl
 }
 
 func init() {
-	DefaultK8SMgr = new(ManagerK8s)
+	DefaultK8SMgr = &ManagerK8s{alerts: newAlertEngine(), monitors: newMonitorControl()}
 }
 func (manage *ManagerK8s) Init(conf, systemNamespace, appNamespace string) error {
 	manage.k8sConfig = conf
@@ -55,9 +100,22 @@ func (manage *ManagerK8s) Init(conf, systemNamespace, appNamespace string) error
 	manage.appNamespace = appNamespace
 	manage.api = new(k8sApi)
 	manage.containerCache = new(ContainerCache)
+	manage.nodeCache = new(NodeCache)
+	if manage.alerts == nil {
+		manage.alerts = newAlertEngine()
+	}
+	if manage.monitors == nil {
+		manage.monitors = newMonitorControl()
+	}
 	if err := manage.api.init(manage.k8sConfig, manage.systemNamespace, manage.appNamespace); err != nil {
 		return logger.Error("init k8s api failed, error[%s]", err)
 	}
+	source := manage.options.MetricsSource
+	if source == "" {
+		source = manage.api.detectMetricsSource()
+	}
+	manage.api.setMetricsSource(source)
+	logger.Info("资源信息的来源: %s", source)
 	return nil
 }
 func (manage *ManagerK8s) Start() {
@@ -65,15 +123,94 @@ func (manage *ManagerK8s) Start() {
 	manage.eventExitCh = make(chan bool)
 	go manage.api.watchPodEvents(manage.systemNamespace)
 	go manage.api.watchPodEvents(manage.appNamespace)
+	go manage.api.watchNodeEvents()
+	go manage.collectMetrics(manage.systemNamespace)
+	go manage.collectMetrics(manage.appNamespace)
 }
 func (manage *ManagerK8s) Stop() {
 	logger.Info("==============k8s stop=============")
 	close(manage.eventExitCh)
 	manage.containerCache = nil
+	manage.nodeCache = nil
 	manage.api.exit()
 }
 
 func (manage *ManagerK8s) StatefulSetCreate(info *CreateReqInfo, isTry bool) error {
+	if err := manage.checkCreate(info); err != nil {
+		return err
+	}
+	if info.NodeName == "" {
+		_, err := manage.statefulSetCreateAuto(info, isTry)
+		return err
+	}
+	if err := manage.checkHostPorts(info); err != nil {
+		return err
+	}
+	if err := manage.checkNodeAffinity(info); err != nil {
+		return err
+	}
+	return manage.statefulSetCreate(info, isTry)
+}
+
+// checkCreate 创建前的参数校验以及策略审核, 与节点无关
+func (manage *ManagerK8s) checkCreate(info *CreateReqInfo) error {
+	if err := info.Validate(); err != nil {
+		logger.Warn("【容器: %s】create container 参数校验失败: %v", info.Name, err)
+		return err
+	}
+	if err := manage.validateUlimitSupport(info); err != nil {
+		return logger.Warn("【容器: %s】create container 参数校验失败: %v", info.Name, err)
+	}
+	return manage.checkPolicies(PolicyCreate, info)
+}
+
+// statefulSetCreate 创建app, 调用前已完成 checkCreate 以及目标节点的校验(主机端口, 节点亲和性)
+func (manage *ManagerK8s) statefulSetCreate(info *CreateReqInfo, isTry bool) error {
+	createInfo, err := manage.buildCreateInfo(info)
+	if err != nil {
+		return err
+	}
+	logger.Info("【容器: %s】create container 命令执行中...目标服务器: %s ", info.Name, info.NodeName)
+	return manage.api.statefulSetCreate(manage.appNamespace, createInfo, isTry)
+}
+
+// StatefulSetUpdate 更新app的配置, NodeName为空时保持app当前的节点, app的运行状态(副本数)保持不变
+func (manage *ManagerK8s) StatefulSetUpdate(info *CreateReqInfo, isTry bool) error {
+	if err := info.Validate(); err != nil {
+		logger.Warn("【容器: %s】update container 参数校验失败: %v", info.Name, err)
+		return err
+	}
+	if err := manage.validateUlimitSupport(info); err != nil {
+		return logger.Warn("【容器: %s】update container 参数校验失败: %v", info.Name, err)
+	}
+	if err := manage.checkPolicies(PolicyUpdate, info); err != nil {
+		return err
+	}
+	if info.NodeName == "" {
+		nodeName, err := manage.api.statefulSetNodeName(info.Name, manage.appNamespace)
+		if err != nil {
+			return err
+		}
+		placed := *info
+		placed.NodeName = nodeName
+		info = &placed
+	}
+	if err := manage.checkHostPorts(info); err != nil {
+		return err
+	}
+	if err := manage.checkNodeAffinity(info); err != nil {
+		return err
+	}
+	createInfo, err := manage.buildCreateInfo(info)
+	if err != nil {
+		return err
+	}
+	logger.Info("【容器: %s】update container 命令执行中...目标服务器: %s ", info.Name, info.NodeName)
+	return manage.api.statefulSetUpdate(manage.appNamespace, createInfo, isTry)
+}
+
+// buildCreateInfo 将创建请求转换为创建StatefulSet的信息
+func (manage *ManagerK8s) buildCreateInfo(info *CreateReqInfo) (*ContainerCreateInfo, error) {
 	createInfo := &ContainerCreateInfo{Name: info.Name, NodeName: info.NodeName, Image: info.Image, HostNetwork: info.HostNetwork, Restart: info.Restart} // 只考虑两种要么是host模式要么是非host模式,
 	// label 标签
 	createInfo.Label = make(map[string]string)
@@ -81,8 +218,8 @@ func (manage *ManagerK8s) StatefulSetCreate(info *CreateReqInfo, isTry bool) err
 		createInfo.Label[label.Key] = label.Value
 	}
 	// 添加特殊标签
-	createInfo.Label["node_ip"] = info.NodeName
-	createInfo.Label["app"] = info.Name
+	createInfo.Label[labelNodeIP] = info.NodeName
+	createInfo.Label[labelApp] = info.Name
 	// env 环境变量
 	for _, envInfo := range info.Env {
 		if envInfo.Key != ENV_MACADDRESS && envInfo.Key != ENV_PRIVILEGED && envInfo.Key != ENV_ULIMIT_NAME {
@@ -95,34 +232,68 @@ func (manage *ManagerK8s) StatefulSetCreate(info *CreateReqInfo, isTry bool) err
 					createInfo.Privileged = true
 				}
 			case ENV_ULIMIT_NAME:
-				logger.Info("【容器: %s】set uLimit value [%s], k8s服务不支持,可直接配置主机系统级别的uLimit,默认会使用主机!", envInfo.Value)
+				if err := manage.applyUlimit(createInfo, envInfo.Value); err != nil {
+					return nil, logger.Warn("【容器: %s】set uLimit value [%s] 失败: %v", info.Name, envInfo.Value, err)
+				}
 			case ENV_MACADDRESS:
-				logger.Info("【容器: %s】set macAddress value [%s], k8s服务不支持!", envInfo.Value)
+				if err := manage.applyMacAddress(createInfo, envInfo.Value); err != nil {
+					return nil, logger.Warn("【容器: %s】set macAddress value [%s] 失败: %v", info.Name, envInfo.Value, err)
+				}
 			}
 		}
 	}
-	// port 端口,非host模式下支持配置: Protocol 协议默认TCP, 仅仅支持: TCP,UDP,SCTP
-	if !info.HostNetwork {
-		for _, port := range info.Port {
-			if port.Protocol == "TCP" || port.Protocol == "UDP" || port.Protocol == "SCTP" {
-				createInfo.Port = append(createInfo.Port, corev1.ContainerPort{ContainerPort: int32(port.InnerPort), HostPort: int32(port.OuterPort), Protocol: corev1.Protocol(port.Protocol)})
-			} else {
-				return logger.Warn("【容器: %s】port protocol[%s] is not support, only support: TCP,UDP,SCTP", info.Name, port.Protocol)
-			}
+	// port 端口, Protocol 协议仅仅支持: TCP,UDP,SCTP; host模式下仅记录容器端口(即主机端口), 用于主机端口冲突检测
+	for _, port := range info.Port {
+		if info.HostNetwork {
+			createInfo.Port = append(createInfo.Port, corev1.ContainerPort{ContainerPort: int32(port.InnerPort), Protocol: corev1.Protocol(port.Protocol)})
+		} else {
+			createInfo.Port = append(createInfo.Port, corev1.ContainerPort{ContainerPort: int32(port.InnerPort), HostPort: int32(port.OuterPort), Protocol: corev1.Protocol(port.Protocol)})
 		}
 	}
 	// volume 卷映射
-	volumeName := info.Name + "-data"
-	for _, volume := range info.Volume {
+	for i, volume := range info.Volume {
+		volumeName := info.Name + "-data"
+		if i > 0 {
+			volumeName = fmt.Sprintf("%s-data-%d", info.Name, i)
+		}
 		createInfo.Volumes = append(createInfo.Volumes, corev1.Volume{Name: volumeName, VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: volume.OuterPath, Type: (*corev1.HostPathType)(proto.String(string(corev1.HostPathDirectoryOrCreate)))}}})
 		createInfo.VolumeMounts = append(createInfo.VolumeMounts, corev1.VolumeMount{Name: volumeName, MountPath: volume.InnerPath})
 	}
-	logger.Info("【容器: %s】create container 命令执行中...目标服务器: %s ", info.Name, info.NodeName)
-	return manage.api.statefulSetCreate(manage.appNamespace, createInfo, isTry)
+	// init容器以及边车容器
+	applyExtraContainers(createInfo, info)
+	// 调度控制
+	applyScheduling(createInfo, info)
+	// 镜像拉取的认证以及拉取策略
+	applyImagePull(createInfo, info)
+	// security 细粒度的安全配置
+	applySecurity(createInfo, info.Security)
+	// resource 资源申请以及限制
+	createInfo.Resources = resourceRequirements(info.Resource)
+	// command 启动命令以及生命周期钩子
+	createInfo.Command = info.Command
+	createInfo.Args = info.Args
+	createInfo.WorkingDir = info.WorkingDir
+	createInfo.PreStop = lifecycleHandler(info.PreStop)
+	createInfo.GracePeriod = info.TerminationGracePeriod
+	postStart, err := mergePostStart(createInfo.UlimitHook, info.PostStart)
+	if err != nil {
+		return nil, logger.Warn("【容器: %s】set postStart hook [%s] 失败: %v", info.Name, hookDescription(info.PostStart), err)
+	}
+	createInfo.PostStart = postStart
+	return createInfo, nil
 }
 func (manage *ManagerK8s) StatefulSetDelete(name string, isTry bool) error {
 	logger.Info("【容器: %s】delete container 命令执行中...", name)
-	return manage.api.statefulSetDelete(name, manage.appNamespace, isTry)
+	if err := manage.api.statefulSetDelete(name, manage.appNamespace, isTry); err != nil {
+		return err
+	}
+	// app删除后清除采集状态以及历史记录, pod删除(重启)时保留
+	if !isTry {
+		manage.monitors.forget(name + "_" + manage.appNamespace)
+		manage.containerCache.history.Delete(name + "_" + manage.appNamespace)
+		manage.forgetApp(name, manage.appNamespace)
+	}
+	return nil
 }
 
 func (manage *ManagerK8s) StatefulSetRunOrStop(name, action string, isTry bool) error {
@@ -174,6 +345,15 @@ func (manage *ManagerK8s) GetCacheStatInfo(name string, isSys bool) (StatInfo, e
 		namespace = manage.appNamespace
 	}
 	cacheInfo, ok := manage.containerCache.getCacheStatInfo(name + "_" + namespace)
+	// 停止采集的app不返回资源信息, 暂停采集的app只返回最后一次的资源信息, 都不重新查询
+	switch manage.MonitorStatus(name, namespace) {
+	case MonitorStopped:
+		return StatInfo{}, fmt.Errorf("%w: %s", ErrMonitorStopped, name)
+	case MonitorPaused:
+		if !ok {
+			return StatInfo{}, fmt.Errorf("%w: %s, no cached stat info", ErrMonitorPaused, name)
+		}
+	}
 	if ok {
 		return cacheInfo, nil
 	} else {
@@ -181,19 +361,27 @@ func (manage *ManagerK8s) GetCacheStatInfo(name string, isSys bool) (StatInfo, e
 			return StatInfo{}, err
 		} else {
 			manage.SetCacheStatInfo(name, namespace, info)
-			go manage.containerCache.addMonitor(name, namespace)
 			return info, nil
 		}
 	}
 }
 func (manage *ManagerK8s) SetCacheContainerInfo(name string, namespace string, info ContainerInfo) {
 	logger.Info("【容器: %s】 set container cache info 命令执行中... ", name)
+	prev, ok := manage.containerCache.getCacheContainerInfo(name + "_" + namespace)
 	manage.containerCache.setCacheContainerInfo(name+"_"+namespace, info)
+	// app重启后发送事件通知并立即评估告警规则
+	if ok && manage.alerts != nil && info.ReStartCount > prev.ReStartCount {
+		manage.NotifyEvent(crashNotification(namespace, prev, info))
+		manage.alerts.recordRestarts(name+"_"+namespace, info.ReStartCount-prev.ReStartCount, time.Now())
+		manage.evaluateAlerts(namespace)
+	}
 }
 
 func (manage *ManagerK8s) SetCacheStatInfo(name string, namespace string, info StatInfo) {
 	logger.Info("【容器: %s】 set container cache stat info 命令执行中... ", name)
-	manage.containerCache.setCacheStatInfo(name+"_"+namespace, info)
+	if !manage.storeStatInfo(name+"_"+namespace, info) {
+		logger.Warn("【容器: %s】已停止资源信息采集, 忽略资源信息", name)
+	}
 }
 
 func (manage *ManagerK8s) DelContainerStatInfo(name string, namespace string) {
@@ -205,11 +393,20 @@ func (manage *ManagerK8s) DelCacheContainerMonitor(name string, namespace string
 	manage.containerCache.delCacheContainerMonitor(name + "_" + namespace)
 }
 
+// InitStatByNamespace 首次启动时批量采集一次空间下的资源信息, 之后由定时采集更新
 func (manage *ManagerK8s) InitStatByNamespace(appNames []string, isSys bool) {
 	logger.Info("【是否为系统组件: %v】 init container cache statInfo 命令执行中... ", isSys)
+	namespace := manage.appNamespace
+	if isSys {
+		namespace = manage.systemNamespace
+	}
+	if err := manage.collectOnce(namespace); err != nil {
+		logger.Info("【空间: %s】 首次启动初始化Stat的时候出现异常: %v", namespace, err)
+		return
+	}
 	for _, name := range appNames {
-		if _, err := manage.GetCacheStatInfo(name, isSys); err != nil {
-			logger.Info("【容器: %s】 首次启动初始化Stat的时候出现异常: %v", name, err)
+		if _, ok := manage.containerCache.getCacheStatInfo(name + "_" + namespace); !ok {
+			logger.Info("【容器: %s】 首次启动初始化Stat的时候未获取到资源信息", name)
 		}
 	}
 }
@@ -219,10 +416,76 @@ func (manage *ManagerK8s) GetAppNamesByNamespace(isSystem bool) ([]string, error
 }
 func (manage *ManagerK8s) GetAllStatInfoOfSortByCpu(desc bool, namespace string) []*StatInfo {
 	logger.Info("【空间: %s】 get container cache all statInfo by cpu desc: %v命令执行中... ", namespace, desc)
-	return manage.containerCache.getAllStatInfoOfSortByCpu(desc, namespace)
+	return manage.queryStatInfos(StatSortCpuRatio, desc, namespace)
 }
 
 func (manage *ManagerK8s) GetAllStatInfoOfSortByMem(desc bool, namespace string) []*StatInfo {
 	logger.Info("【空间: %s】 get container cache all statInfo by mem desc: %v命令执行中... ", namespace, desc)
-	return manage.containerCache.getAllStatInfoOfSortByMem(desc, namespace)
+	return manage.queryStatInfos(StatSortMemUsed, desc, namespace)
+}
+
+// GetAllStatInfoOfSortByNet 按网络速率(接收+发送)排序
+func (manage *ManagerK8s) GetAllStatInfoOfSortByNet(desc bool, namespace string) []*StatInfo {
+	logger.Info("【空间: %s】 get container cache all statInfo by net desc: %v命令执行中... ", namespace, desc)
+	return manage.queryStatInfos(StatSortNet, desc, namespace)
+}
+
+// GetAllStatInfoOfSortByDisk 按磁盘占用(容器可写层+卷)排序
+func (manage *ManagerK8s) GetAllStatInfoOfSortByDisk(desc bool, namespace string) []*StatInfo {
+	logger.Info("【空间: %s】 get container cache all statInfo by disk desc: %v命令执行中... ", namespace, desc)
+	return manage.queryStatInfos(StatSortDisk, desc, namespace)
+}
+
+func (manage *ManagerK8s) ListNodes() ([]NodeInfo, error) {
+	logger.Info("get all node info 命令执行中... ")
+	nodes := manage.nodeCache.getAllNodeInfo()
+	if len(nodes) == 0 {
+		// 节点监听器尚未同步, 直接查询
+		infos, err := manage.api.listNodes()
+		if err != nil {
+			return nil, err
+		}
+		for _, info := range infos {
+			manage.nodeCache.setCacheNodeInfo(info)
+		}
+		nodes = infos
+	}
+	states, err := manage.appStatesByNode()
+	if err != nil {
+		return nil, err
+	}
+	for i := range nodes {
+		fillNodeAppStates(&nodes[i], states[nodes[i].Name])
+	}
+	return nodes, nil
+}
+
+func (manage *ManagerK8s) GetNode(name string) (NodeInfo, error) {
+	logger.Info("【节点: %s】 get node info 命令执行中... ", name)
+	info, ok := manage.nodeCache.getCacheNodeInfo(name)
+	if !ok {
+		var err error
+		if info, err = manage.api.getNode(name); err != nil {
+			return NodeInfo{}, err
+		}
+		manage.nodeCache.setCacheNodeInfo(info)
+	}
+	states, err := manage.appStatesByNode()
+	if err != nil {
+		return NodeInfo{}, err
+	}
+	fillNodeAppStates(&info, states[name])
+	return info, nil
+}
+
+func (manage *ManagerK8s) SetCacheNodeInfo(info NodeInfo) {
+	if cacheInfo, ok := manage.nodeCache.getCacheNodeInfo(info.Name); ok && cacheInfo.Status != info.Status {
+		logger.Warn("【节点: %s】 节点状态变更: %s -> %s", info.Name, cacheInfo.Status, info.Status)
+	}
+	manage.nodeCache.setCacheNodeInfo(info)
+}
+
+func (manage *ManagerK8s) DelCacheNodeInfo(name string) {
+	logger.Info("【节点: %s】 delete node cache 命令执行中... ", name)
+	manage.nodeCache.delCacheNodeInfo(name)
 }
@@ -0,0 +1,233 @@
+package k8s
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+/**
+ *    Description: Prometheus 文本格式的监控指标导出, 包括app的资源信息以及管理器内部的运行指标
+ *    Date: 2026/10/19
+ */
+
+const metricsPrefix = "k8s_core_"
+
+type (
+	// managerMetrics 管理器内部的运行指标
+	managerMetrics struct {
+		lock            sync.Mutex
+		watchReconnects map[string]uint64 // key: 监听类型/空间
+		apiErrors       map[string]uint64 // key: 操作
+		pollSeconds     map[string]float64
+		pollCount       map[string]uint64
+		pollLast        map[string]float64
+	}
+	// appMetric 单个app的指标
+	appMetric struct {
+		namespace string
+		info      ContainerInfo
+		stat      StatInfo
+		hasStat   bool
+	}
+)
+
+// internalMetrics 全局的管理器内部指标
+var internalMetrics = &managerMetrics{
+	watchReconnects: make(map[string]uint64),
+	apiErrors:       make(map[string]uint64),
+	pollSeconds:     make(map[string]float64),
+	pollCount:       make(map[string]uint64),
+	pollLast:        make(map[string]float64),
+}
+
+// watchReconnect 记录监听器重新创建, watch: pods, nodes
+func (metrics *managerMetrics) watchReconnect(watch, namespace string) {
+	metrics.lock.Lock()
+	defer metrics.lock.Unlock()
+	metrics.watchReconnects[watch+"/"+namespace]++
+}
+
+// apiError 记录k8s api调用失败
+func (metrics *managerMetrics) apiError(operation string) {
+	metrics.lock.Lock()
+	defer metrics.lock.Unlock()
+	metrics.apiErrors[operation]++
+}
+
+// observePoll 记录一次资源信息采集的耗时
+func (metrics *managerMetrics) observePoll(namespace string, duration time.Duration) {
+	metrics.lock.Lock()
+	defer metrics.lock.Unlock()
+	metrics.pollSeconds[namespace] += duration.Seconds()
+	metrics.pollCount[namespace]++
+	metrics.pollLast[namespace] = duration.Seconds()
+}
+
+// MetricsHandler 返回 Prometheus 指标的 http.Handler, 例如: http.Handle("/metrics", k8s.DefaultK8SMgr.MetricsHandler())
+func (manage *ManagerK8s) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = w.Write([]byte(manage.metricsText()))
+	})
+}
+
+// metricsText 生成 Prometheus 文本格式的指标
+func (manage *ManagerK8s) metricsText() string {
+	var b strings.Builder
+	apps := manage.appMetrics()
+	writeFamily(&b, "app_cpu_used_millicores", "gauge", "CPU used by all containers of the app in millicores.")
+	for _, app := range apps {
+		if app.hasStat {
+			writeSample(&b, "app_cpu_used_millicores", app.labels(), float64(app.stat.CpuLoad.Used))
+		}
+	}
+	writeFamily(&b, "app_cpu_ratio_percent", "gauge", "CPU used by the app relative to the node capacity in percent.")
+	for _, app := range apps {
+		if app.hasStat {
+			writeSample(&b, "app_cpu_ratio_percent", app.labels(), app.stat.CpuLoad.Ratio)
+		}
+	}
+	writeFamily(&b, "app_memory_used_megabytes", "gauge", "Memory used by all containers of the app in MB.")
+	for _, app := range apps {
+		if app.hasStat {
+			writeSample(&b, "app_memory_used_megabytes", app.labels(), float64(app.stat.MemLoad.Used))
+		}
+	}
+	writeFamily(&b, "app_memory_ratio_percent", "gauge", "Memory used by the app relative to the node capacity in percent.")
+	for _, app := range apps {
+		if app.hasStat {
+			writeSample(&b, "app_memory_ratio_percent", app.labels(), app.stat.MemLoad.Ratio)
+		}
+	}
+	writeFamily(&b, "app_network_receive_bytes_total", "counter", "Bytes received by the app pod, only available with the kubelet metrics source.")
+	for _, app := range apps {
+		if app.hasStat && !app.stat.NetLoad.At.IsZero() {
+			writeSample(&b, "app_network_receive_bytes_total", app.labels(), float64(app.stat.NetLoad.RxBytes))
+		}
+	}
+	writeFamily(&b, "app_network_transmit_bytes_total", "counter", "Bytes transmitted by the app pod, only available with the kubelet metrics source.")
+	for _, app := range apps {
+		if app.hasStat && !app.stat.NetLoad.At.IsZero() {
+			writeSample(&b, "app_network_transmit_bytes_total", app.labels(), float64(app.stat.NetLoad.TxBytes))
+		}
+	}
+	writeFamily(&b, "app_disk_used_megabytes", "gauge", "Disk used by the app in MB by type (rootfs, volume), only available with the kubelet metrics source.")
+	for _, app := range apps {
+		if app.hasStat && !app.stat.NetLoad.At.IsZero() {
+			writeSample(&b, "app_disk_used_megabytes", append(app.labels(), "type", "rootfs"), float64(app.stat.DiskLoad.RootfsUsed))
+			writeSample(&b, "app_disk_used_megabytes", append(app.labels(), "type", "volume"), float64(app.stat.DiskLoad.VolumeUsed))
+		}
+	}
+	writeFamily(&b, "app_restart_count", "gauge", "Restart count of all containers of the app.")
+	for _, app := range apps {
+		writeSample(&b, "app_restart_count", app.labels(), float64(app.info.ReStartCount))
+	}
+	writeFamily(&b, "app_phase", "gauge", "Phase of the app pod, the value is always 1.")
+	for _, app := range apps {
+		writeSample(&b, "app_phase", append(app.labels(), "phase", app.info.Status), 1)
+	}
+
+	counts := make(map[string]int)
+	for _, app := range apps {
+		counts[app.namespace]++
+	}
+	writeFamily(&b, "manager_cached_apps", "gauge", "Number of apps in the container cache.")
+	for _, namespace := range []string{manage.systemNamespace, manage.appNamespace} {
+		writeSample(&b, "manager_cached_apps", []string{"namespace", namespace}, float64(counts[namespace]))
+	}
+
+	internalMetrics.lock.Lock()
+	defer internalMetrics.lock.Unlock()
+	writeFamily(&b, "manager_watch_reconnects_total", "counter", "Number of times a watch was recreated.")
+	for _, key := range sortedCounterKeys(internalMetrics.watchReconnects) {
+		watch, namespace, _ := strings.Cut(key, "/")
+		writeSample(&b, "manager_watch_reconnects_total", []string{"watch", watch, "namespace", namespace}, float64(internalMetrics.watchReconnects[key]))
+	}
+	writeFamily(&b, "manager_api_errors_total", "counter", "Number of failed kubernetes api calls by operation.")
+	for _, key := range sortedCounterKeys(internalMetrics.apiErrors) {
+		writeSample(&b, "manager_api_errors_total", []string{"operation", key}, float64(internalMetrics.apiErrors[key]))
+	}
+	writeFamily(&b, "manager_poll_duration_seconds", "summary", "Duration of the metrics collection of a namespace.")
+	for _, namespace := range sortedCounterKeys(internalMetrics.pollCount) {
+		writeSample(&b, "manager_poll_duration_seconds_sum", []string{"namespace", namespace}, internalMetrics.pollSeconds[namespace])
+		writeSample(&b, "manager_poll_duration_seconds_count", []string{"namespace", namespace}, float64(internalMetrics.pollCount[namespace]))
+	}
+	writeFamily(&b, "manager_poll_last_duration_seconds", "gauge", "Duration of the last metrics collection of a namespace.")
+	for _, namespace := range sortedCounterKeys(internalMetrics.pollCount) {
+		writeSample(&b, "manager_poll_last_duration_seconds", []string{"namespace", namespace}, internalMetrics.pollLast[namespace])
+	}
+	return b.String()
+}
+
+// appMetrics 获取缓存中所有app的容器信息以及资源信息, 按空间以及名称排序
+func (manage *ManagerK8s) appMetrics() []appMetric {
+	var apps []appMetric
+	manage.containerCache.cache.Range(func(key, value any) bool {
+		monitor, ok := value.(ContainerMonitor)
+		if !ok {
+			return true
+		}
+		for _, namespace := range []string{manage.systemNamespace, manage.appNamespace} {
+			if name := key.(string); strings.HasSuffix(name, "_"+namespace) {
+				app := appMetric{namespace: namespace, info: ContainerInfo{Name: strings.TrimSuffix(name, "_"+namespace)}}
+				if monitor.containerInfo != nil {
+					app.info = *monitor.containerInfo
+				}
+				if monitor.statInfo != nil {
+					app.stat, app.hasStat = *monitor.statInfo, true
+				}
+				apps = append(apps, app)
+				break
+			}
+		}
+		return true
+	})
+	sort.Slice(apps, func(i, j int) bool {
+		if apps[i].namespace != apps[j].namespace {
+			return apps[i].namespace < apps[j].namespace
+		}
+		return apps[i].info.Name < apps[j].info.Name
+	})
+	return apps
+}
+
+func (app appMetric) labels() []string {
+	return []string{"namespace", app.namespace, "app", app.info.Name, "node", app.info.HostIP}
+}
+
+func writeFamily(b *strings.Builder, name, metricType, help string) {
+	fmt.Fprintf(b, "# HELP %s%s %s\n# TYPE %s%s %s\n", metricsPrefix, name, help, metricsPrefix, name, metricType)
+}
+
+// writeSample 输出一个指标, labels 为 key, value 交替的列表
+func writeSample(b *strings.Builder, name string, labels []string, value float64) {
+	b.WriteString(metricsPrefix + name)
+	if len(labels) > 0 {
+		b.WriteString("{")
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			fmt.Fprintf(b, "%s=\"%s\"", labels[i], escapeLabelValue(labels[i+1]))
+		}
+		b.WriteString("}")
+	}
+	fmt.Fprintf(b, " %v\n", value)
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+func sortedCounterKeys[V uint64 | float64](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,8 +1,6 @@
 package k8s
 
 import (
-	logger "github.com/alecthomas/log4go"
-	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -15,9 +13,38 @@ import (
 
 type (
 	StatInfo struct {
-		Name    string   `json:"name"`
-		CpuLoad LoadInfo `json:"CpuLoad"`
-		MemLoad LoadInfo `json:"MemLoad"`
+		Name       string          `json:"name"`
+		CpuLoad    LoadInfo        `json:"CpuLoad"` // app所有容器之和
+		MemLoad    LoadInfo        `json:"MemLoad"` // app所有容器之和
+		NetLoad    NetInfo         `json:"NetLoad"` // pod网络, 仅 kubelet 来源支持
+		DiskLoad   DiskInfo        `json:"DiskLoad"`
+		Containers []ContainerStat `json:"Containers"`
+	}
+	// ContainerStat 单个容器的资源占用
+	ContainerStat struct {
+		Name       string   `json:"name"`
+		CpuLoad    LoadInfo `json:"CpuLoad"`
+		MemLoad    LoadInfo `json:"MemLoad"`
+		RootfsUsed uint64   `json:"RootfsUsed"` // 容器可写层占用, 单位MB, 仅 kubelet 来源支持
+	}
+	// NetInfo pod的网络流量, 速率根据相邻两次采集计算
+	NetInfo struct {
+		RxBytes uint64    // 累计接收字节数
+		TxBytes uint64    // 累计发送字节数
+		RxRate  float64   // 接收速率, 单位: byte/s
+		TxRate  float64   // 发送速率, 单位: byte/s
+		At      time.Time // 采集时间
+	}
+	// DiskInfo 磁盘占用, 单位MB, 仅 kubelet 来源支持; hostPath 卷的占用 kubelet 不统计
+	DiskInfo struct {
+		RootfsUsed uint64        // 所有容器可写层之和
+		VolumeUsed uint64        // 所有卷之和
+		Volumes    []VolumeUsage // 卷的占用
+	}
+	VolumeUsage struct {
+		Name     string
+		Used     uint64
+		Capacity uint64
 	}
 	LoadInfo struct {
 		Used  uint64  //内存单位byte
@@ -26,65 +53,38 @@ type (
 	}
 	ContainerInfo struct {
 		Name         string
+		NodeName     string
 		HostIP       string
 		PodIP        string
 		Status       string
-		ReStartCount int
+		ReStartCount int // 所有容器重启次数之和
 		NewStartAt   time.Time
+		Labels       map[string]string // pod的标签, 包含app的标签
+		Containers   []ContainerStateInfo
+	}
+	// ContainerStateInfo 单个容器的状态
+	ContainerStateInfo struct {
+		Name         string
+		Image        string
+		Init         bool // 是否为init容器
+		Ready        bool
+		State        string // Running, Waiting, Terminated
+		Reason       string
+		LastReason   string // 上次退出的原因, 例如 Error, OOMKilled
+		ReStartCount int
+		StartAt      time.Time
 	}
 	ContainerMonitor struct {
 		statInfo      *StatInfo
 		containerInfo *ContainerInfo
-		statsExitCh   chan bool
 	}
 	// ContainerCache 容器监控缓存
 	ContainerCache struct {
-		cache sync.Map
+		cache   sync.Map
+		history sync.Map // app资源信息的历史记录, value: *statHistory
 	}
 )
 
-func (cache *ContainerCache) addMonitor(name, namespace string) {
-	var (
-		monitor ContainerMonitor
-		success bool
-	)
-	if body, ok := cache.cache.Load(name + "_" + namespace); ok {
-		monitor, success = body.(ContainerMonitor)
-		if !success || monitor.statInfo == nil {
-			return
-		}
-	}
-	timer := time.NewTimer(time.Second * 3)
-	defer timer.Stop()
-	for {
-		select {
-		case <-timer.C:
-			if stat, err := DefaultK8SMgr.StatInfo(monitor.statInfo.Name, namespace); err != nil {
-				logger.Info("【容器: %v】定时获取容器资源信息异常: %v", monitor.statInfo.Name, err)
-				DefaultK8SMgr.DelContainerStatInfo(monitor.statInfo.Name, namespace)
-				return
-			} else {
-				DefaultK8SMgr.SetCacheStatInfo(monitor.statInfo.Name, namespace, StatInfo{
-					Name: monitor.statInfo.Name,
-					CpuLoad: LoadInfo{
-						Ratio: stat.CpuLoad.Ratio,
-						Used:  stat.CpuLoad.Used,
-						Total: stat.CpuLoad.Total,
-					},
-					MemLoad: LoadInfo{
-						Ratio: stat.MemLoad.Ratio,
-						Used:  stat.MemLoad.Used,
-						Total: stat.MemLoad.Total,
-					},
-				})
-			}
-			timer.Reset(time.Second * 3)
-		case <-monitor.statsExitCh:
-			logger.Info("【容器: %v】容器正常的进行下线, 停止采集容器相关资源信息！", monitor.statInfo.Name)
-			return
-		}
-	}
-}
 func (cache *ContainerCache) getCacheContainerInfo(name string) (ContainerInfo, bool) {
 	if body, ok := cache.cache.Load(name); ok {
 		monitor, success := body.(ContainerMonitor)
@@ -114,7 +114,6 @@ func (cache *ContainerCache) setCacheContainerInfo(name string, containerInfo Co
 	}
 	monitor := ContainerMonitor{
 		containerInfo: &containerInfo,
-		statsExitCh:   make(chan bool),
 	}
 	cache.cache.Store(name, monitor)
 }
@@ -129,8 +128,7 @@ func (cache *ContainerCache) setCacheStatInfo(name string, statInfo StatInfo) {
 		}
 	}
 	monitor := ContainerMonitor{
-		statInfo:    &statInfo,
-		statsExitCh: make(chan bool),
+		statInfo: &statInfo,
 	}
 	cache.cache.Store(name, monitor)
 }
@@ -144,47 +142,20 @@ func (cache *ContainerCache) delContainerStatInfo(name string) {
 		}
 	}
 }
+
+// delCacheContainerMonitor pod删除(重启, 迁移)时清除缓存, 历史记录保留, 仅在app删除或者停止采集时清除
 func (cache *ContainerCache) delCacheContainerMonitor(name string) {
-	if body, ok := cache.cache.Load(name); ok {
-		monitor, success := body.(ContainerMonitor)
-		if success && monitor.statInfo != nil {
-			close(monitor.statsExitCh)
-		}
-	}
 	cache.cache.Delete(name)
 }
 
-// getAllStats 获取所有nameSpace 下的app的StatInfo
-func (cache *ContainerCache) getAllStats(nameSpace string) []*StatInfo {
-	stats := make([]*StatInfo, 0)
+// statNames 获取空间下有资源信息的app名称
+func (cache *ContainerCache) statNames(namespace string) []string {
+	var names []string
 	cache.cache.Range(func(key, value any) bool {
-		if strings.HasSuffix(key.(string), nameSpace) {
-			if statInfo, ok := cache.getCacheStatInfo(key.(string)); ok {
-				stats = append(stats, &statInfo)
-			} else {
-				stats = append(stats, &StatInfo{Name: strings.TrimRight(key.(string), "_"+nameSpace), CpuLoad: LoadInfo{Ratio: 0, Used: 0}, MemLoad: LoadInfo{Ratio: 0, Used: 0}})
-			}
+		if monitor, ok := value.(ContainerMonitor); ok && monitor.statInfo != nil && strings.HasSuffix(key.(string), "_"+namespace) {
+			names = append(names, strings.TrimSuffix(key.(string), "_"+namespace))
 		}
 		return true
 	})
-	return stats
-}
-func (cache *ContainerCache) getAllStatInfoOfSortByCpu(desc bool, nameSpace string) []*StatInfo {
-	stats := cache.getAllStats(nameSpace)
-	if desc {
-		sort.Sort(CpuDescSorter(stats))
-	} else {
-		sort.Sort(CpuAscSorter(stats))
-	}
-	return stats
-}
-
-func (cache *ContainerCache) getAllStatInfoOfSortByMem(desc bool, nameSpace string) []*StatInfo {
-	stats := cache.getAllStats(nameSpace)
-	if desc {
-		sort.Sort(MemDescSorter(stats))
-	} else {
-		sort.Sort(MemAscSorter(stats))
-	}
-	return stats
+	return names
 }
@@ -0,0 +1,208 @@
+package k8s
+
+import (
+	"errors"
+	"fmt"
+	logger "github.com/alecthomas/log4go"
+	"sync"
+	"time"
+)
+
+/**
+ *    Description: 资源信息采集的控制: 每个空间的采集周期以及失败容忍次数, 失败时退避重试, 单个app的采集启动/停止/暂停
+ *    Date: 2026/10/19
+ */
+
+const (
+	MonitorRunning = "running" // 正常采集
+	MonitorPaused  = "paused"  // 暂停采集, 保留最后一次的资源信息
+	MonitorStopped = "stopped" // 停止采集, 删除资源信息以及历史记录
+
+	defaultCollectInterval = 3 * time.Second
+	defaultMaxFailures     = 3
+	defaultMaxBackoff      = time.Minute
+	minCollectInterval     = time.Second
+)
+
+var (
+	ErrMonitorStopped = errors.New("monitor is stopped")
+	ErrMonitorPaused  = errors.New("monitor is paused")
+)
+
+type (
+	// MonitorOptions 空间的采集配置, 为0的配置使用默认值
+	MonitorOptions struct {
+		Interval    time.Duration // 采集周期, 默认: 3秒, 不小于1秒
+		MaxFailures int           // 连续失败的容忍次数, 达到后清除空间的资源信息; app连续缺少监控数据达到该次数后删除其资源信息, 默认: 3
+		MaxBackoff  time.Duration // 失败后采集周期翻倍, 最大不超过该值, 默认: 1分钟
+	}
+	// monitorControl 采集控制, 空间的配置以及失败次数, app的采集状态以及缺少监控数据的次数
+	monitorControl struct {
+		lock     sync.Mutex
+		options  map[string]MonitorOptions // key: 空间
+		failures map[string]int            // key: 空间
+		states   map[string]string         // key: app_空间, 不存在表示 running
+		misses   map[string]int            // key: app_空间
+	}
+)
+
+func newMonitorControl() *monitorControl {
+	return &monitorControl{options: make(map[string]MonitorOptions), failures: make(map[string]int), states: make(map[string]string), misses: make(map[string]int)}
+}
+
+func (opt MonitorOptions) withDefaults() MonitorOptions {
+	if opt.Interval == 0 {
+		opt.Interval = defaultCollectInterval
+	}
+	if opt.MaxFailures == 0 {
+		opt.MaxFailures = defaultMaxFailures
+	}
+	if opt.MaxBackoff == 0 {
+		opt.MaxBackoff = defaultMaxBackoff
+	}
+	if opt.MaxBackoff < opt.Interval {
+		opt.MaxBackoff = opt.Interval
+	}
+	return opt
+}
+
+// SetMonitorOptions 设置空间的采集配置, 从下一次采集开始生效
+func (manage *ManagerK8s) SetMonitorOptions(namespace string, opt MonitorOptions) error {
+	if namespace == "" {
+		return errors.New("namespace is required")
+	}
+	if opt.Interval != 0 && opt.Interval < minCollectInterval {
+		return fmt.Errorf("collect interval must not be less than %v", minCollectInterval)
+	}
+	if opt.MaxFailures < 0 || opt.MaxBackoff < 0 {
+		return errors.New("maxFailures and maxBackoff must not be negative")
+	}
+	manage.monitors.lock.Lock()
+	defer manage.monitors.lock.Unlock()
+	manage.monitors.options[namespace] = opt.withDefaults()
+	return nil
+}
+
+// GetMonitorOptions 获取空间的采集配置
+func (manage *ManagerK8s) GetMonitorOptions(namespace string) MonitorOptions {
+	manage.monitors.lock.Lock()
+	defer manage.monitors.lock.Unlock()
+	return manage.monitors.namespaceOptions(namespace)
+}
+
+func (control *monitorControl) namespaceOptions(namespace string) MonitorOptions {
+	if opt, ok := control.options[namespace]; ok {
+		return opt
+	}
+	return MonitorOptions{}.withDefaults()
+}
+
+// MonitorStart 启动(恢复)app的资源信息采集
+func (manage *ManagerK8s) MonitorStart(name, namespace string) {
+	logger.Info("【容器: %s】启动资源信息采集 命令执行中... ", name)
+	manage.monitors.lock.Lock()
+	defer manage.monitors.lock.Unlock()
+	delete(manage.monitors.states, name+"_"+namespace)
+}
+
+// MonitorPause 暂停app的资源信息采集, 保留最后一次的资源信息
+func (manage *ManagerK8s) MonitorPause(name, namespace string) {
+	logger.Info("【容器: %s】暂停资源信息采集 命令执行中... ", name)
+	manage.monitors.setState(name+"_"+namespace, MonitorPaused)
+}
+
+// MonitorStop 停止app的资源信息采集, 删除资源信息以及历史记录
+func (manage *ManagerK8s) MonitorStop(name, namespace string) {
+	logger.Info("【容器: %s】停止资源信息采集 命令执行中... ", name)
+	manage.monitors.setState(name+"_"+namespace, MonitorStopped)
+	manage.containerCache.delContainerStatInfo(name + "_" + namespace)
+	manage.containerCache.history.Delete(name + "_" + namespace)
+}
+
+// storeStatInfo 缓存app的资源信息, 停止采集的app不缓存; 与 MonitorStop 使用同一把锁, 避免停止后又写入缓存
+func (manage *ManagerK8s) storeStatInfo(key string, info StatInfo) bool {
+	manage.monitors.lock.Lock()
+	defer manage.monitors.lock.Unlock()
+	if manage.monitors.state(key) == MonitorStopped {
+		return false
+	}
+	manage.containerCache.setCacheStatInfo(key, info)
+	return true
+}
+
+// MonitorStatus 获取app的资源信息采集状态: running, paused, stopped
+func (manage *ManagerK8s) MonitorStatus(name, namespace string) string {
+	manage.monitors.lock.Lock()
+	defer manage.monitors.lock.Unlock()
+	return manage.monitors.state(name + "_" + namespace)
+}
+
+func (control *monitorControl) setState(key, state string) {
+	control.lock.Lock()
+	defer control.lock.Unlock()
+	control.states[key] = state
+	delete(control.misses, key)
+}
+
+func (control *monitorControl) state(key string) string {
+	if state, ok := control.states[key]; ok {
+		return state
+	}
+	return MonitorRunning
+}
+
+// collecting app是否正常采集
+func (control *monitorControl) collecting(key string) bool {
+	control.lock.Lock()
+	defer control.lock.Unlock()
+	return control.state(key) == MonitorRunning
+}
+
+// forget 删除app的采集状态, app(StatefulSet)删除时调用
+func (control *monitorControl) forget(key string) {
+	control.lock.Lock()
+	defer control.lock.Unlock()
+	delete(control.states, key)
+	delete(control.misses, key)
+}
+
+// missed 记录app缺少监控数据, 返回是否达到容忍次数; 采集到监控数据时 count 为false, 重置次数
+func (control *monitorControl) missed(key, namespace string, count bool) bool {
+	control.lock.Lock()
+	defer control.lock.Unlock()
+	if !count {
+		delete(control.misses, key)
+		return false
+	}
+	control.misses[key]++
+	if control.misses[key] < control.namespaceOptions(namespace).MaxFailures {
+		return false
+	}
+	delete(control.misses, key)
+	return true
+}
+
+// nextInterval 根据本次采集的结果计算下一次采集的等待时间, 连续失败时按失败次数退避, 返回是否刚达到容忍次数
+func (control *monitorControl) nextInterval(namespace string, err error) (time.Duration, bool) {
+	control.lock.Lock()
+	defer control.lock.Unlock()
+	opt := control.namespaceOptions(namespace)
+	if err == nil {
+		if control.failures[namespace] > 0 {
+			logger.Info("【空间: %s】资源信息采集恢复, 此前连续失败 %d 次", namespace, control.failures[namespace])
+		}
+		delete(control.failures, namespace)
+		return opt.Interval, false
+	}
+	control.failures[namespace]++
+	failures := control.failures[namespace]
+	backoff := opt.Interval
+	for i := 0; i < failures && backoff < opt.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > opt.MaxBackoff {
+		backoff = opt.MaxBackoff
+	}
+	logger.Warn("【空间: %s】资源信息采集连续失败 %d 次: %v, %v 后重试", namespace, failures, err, backoff)
+	return backoff, failures == opt.MaxFailures
+}
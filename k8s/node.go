@@ -0,0 +1,222 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sort"
+	"sync"
+)
+
+/**
+ *    Description: 节点信息管理, 节点状态以及节点上业务app的运行统计
+ *    Date: 2026/10/19
+ */
+
+const (
+	labelNodeIP      = "node_ip"
+	labelApp         = "app"
+	labelHostname    = "kubernetes.io/hostname"
+	nodeStatsStopped = "Stopped"
+	nodeStatsRunning = "Running"
+	nodeStatsPaused  = "Paused"
+)
+
+type (
+	// NodeCache 节点信息缓存, 由节点监听器实时更新
+	NodeCache struct {
+		cache sync.Map
+	}
+)
+
+func (cache *NodeCache) getCacheNodeInfo(name string) (NodeInfo, bool) {
+	if body, ok := cache.cache.Load(name); ok {
+		if info, success := body.(NodeInfo); success {
+			return info, true
+		}
+	}
+	return NodeInfo{}, false
+}
+func (cache *NodeCache) setCacheNodeInfo(info NodeInfo) {
+	cache.cache.Store(info.Name, info)
+}
+func (cache *NodeCache) delCacheNodeInfo(name string) {
+	cache.cache.Delete(name)
+}
+func (cache *NodeCache) getAllNodeInfo() []NodeInfo {
+	nodes := make([]NodeInfo, 0)
+	cache.cache.Range(func(key, value any) bool {
+		if info, ok := value.(NodeInfo); ok {
+			nodes = append(nodes, info)
+		}
+		return true
+	})
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+	return nodes
+}
+
+// nodeToInfo 将k8s的节点对象转换为NodeInfo, 不包含app的统计数量
+func nodeToInfo(node *corev1.Node) NodeInfo {
+	info := NodeInfo{
+		Name:          node.Name,
+		Status:        NodeUnknown,
+		Unschedulable: node.Spec.Unschedulable,
+		Labels:        node.Labels,
+		DockerVersion: node.Status.NodeInfo.ContainerRuntimeVersion,
+		Capacity:      toResourceInfo(node.Status.Capacity),
+		Allocatable:   toResourceInfo(node.Status.Allocatable),
+	}
+	for _, taint := range node.Spec.Taints {
+		info.Taints = append(info.Taints, TaintInfo{Key: taint.Key, Value: taint.Value, Effect: string(taint.Effect)})
+	}
+	for _, address := range node.Status.Addresses {
+		if address.Type == corev1.NodeInternalIP {
+			info.Addr = address.Address
+			break
+		}
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			if condition.Status == corev1.ConditionTrue {
+				info.Ready = true
+				info.Status = NodeHealthy
+			} else if condition.Status == corev1.ConditionFalse {
+				info.Status = NodeNotReady
+			}
+			break
+		}
+	}
+	return info
+}
+
+func toResourceInfo(list corev1.ResourceList) ResourceInfo {
+	cpu := list[corev1.ResourceCPU]
+	memory := list[corev1.ResourceMemory]
+	pods := list[corev1.ResourcePods]
+	return ResourceInfo{
+		Cpu:    uint64(cpu.MilliValue()),
+		Memory: uint64(memory.Value() / 1024 / 1024),
+		Pods:   uint64(pods.Value()),
+	}
+}
+
+func (api *k8sApi) listNodes() ([]NodeInfo, error) {
+	nodes, err := api.client.CoreV1().Nodes().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	infos := make([]NodeInfo, 0, len(nodes.Items))
+	for i := range nodes.Items {
+		infos = append(infos, nodeToInfo(&nodes.Items[i]))
+	}
+	return infos, nil
+}
+
+func (api *k8sApi) getNode(name string) (NodeInfo, error) {
+	if name == "" {
+		return NodeInfo{}, errors.New("node name is empty")
+	}
+	node, err := api.client.CoreV1().Nodes().Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return NodeInfo{}, err
+	}
+	return nodeToInfo(node), nil
+}
+
+// appStatesByNode 统计每个节点上业务app的数量, key为节点名称
+// Running: 容器运行中; Paused: 已启动但是容器未处于运行状态(拉取镜像,异常等), 根据事件监听维护的pod缓存(节点以及状态)统计;
+// Stopped: 副本数为0, 没有pod, 通过StatefulSet统计
+func (manage *ManagerK8s) appStatesByNode() (map[string]map[string]int, error) {
+	stopped, err := manage.api.stoppedAppsByNode()
+	if err != nil {
+		return nil, err
+	}
+	states := make(map[string]map[string]int)
+	count := func(nodeName, state string, n int) {
+		if _, ok := states[nodeName]; !ok {
+			states[nodeName] = make(map[string]int)
+		}
+		states[nodeName][state] += n
+	}
+	for nodeName, n := range stopped {
+		count(nodeName, nodeStatsStopped, n)
+	}
+	for _, item := range manage.containerCache.queryItems(manage.appNamespace) {
+		if item.Info.NodeName == "" {
+			continue
+		}
+		if item.Info.Status == RunningStatus {
+			count(item.Info.NodeName, nodeStatsRunning, 1)
+		} else {
+			count(item.Info.NodeName, nodeStatsPaused, 1)
+		}
+	}
+	return states, nil
+}
+
+// stoppedAppsByNode 统计每个节点上副本数为0的app数量, 从apiserver的缓存读取(ResourceVersion: 0), 不直接访问etcd
+func (api *k8sApi) stoppedAppsByNode() (map[string]int, error) {
+	statefulSets, err := api.client.AppsV1().StatefulSets(api.appNamespace).List(context.TODO(), metav1.ListOptions{ResourceVersion: "0"})
+	if err != nil {
+		return nil, err
+	}
+	stopped := make(map[string]int)
+	for _, item := range statefulSets.Items {
+		if item.Spec.Replicas != nil && *item.Spec.Replicas > 0 {
+			continue
+		}
+		nodeName := item.Spec.Template.Spec.NodeSelector[labelHostname]
+		if nodeName == "" {
+			nodeName = item.Labels[labelNodeIP]
+		}
+		stopped[nodeName]++
+	}
+	return stopped, nil
+}
+
+// fillNodeAppStates 填充节点上业务app的统计数量
+func fillNodeAppStates(info *NodeInfo, states map[string]int) {
+	info.Running = states[nodeStatsRunning]
+	info.Paused = states[nodeStatsPaused]
+	info.Stopped = states[nodeStatsStopped]
+	info.Total = info.Running + info.Paused + info.Stopped
+}
+
+// nodeCordon 设置节点是否可以调度, unschedulable为true表示禁止调度
+func (api *k8sApi) nodeCordon(name string, unschedulable bool, isTry ...bool) error {
+	node, err := api.client.CoreV1().Nodes().Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if node.Spec.Unschedulable == unschedulable {
+		return nil
+	}
+	node.Spec.Unschedulable = unschedulable
+	if len(isTry) > 0 && isTry[0] {
+		_, err = api.client.CoreV1().Nodes().Update(context.Background(), node, metav1.UpdateOptions{DryRun: []string{"All"}})
+	} else {
+		_, err = api.client.CoreV1().Nodes().Update(context.Background(), node, metav1.UpdateOptions{})
+	}
+	return err
+}
+
+// listAppsByNode 获取固定在节点上的所有业务app名称
+func (api *k8sApi) listAppsByNode(nodeName string) ([]string, error) {
+	statefulSets, err := api.client.AppsV1().StatefulSets(api.appNamespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, item := range statefulSets.Items {
+		appNode := item.Spec.Template.Spec.NodeSelector[labelHostname]
+		if appNode == "" {
+			appNode = item.Labels[labelNodeIP]
+		}
+		if appNode == nodeName {
+			names = append(names, item.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
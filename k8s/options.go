@@ -0,0 +1,41 @@
+package k8s
+
+import (
+	"fmt"
+	"time"
+)
+
+/**
+ *    Description: 集群相关的配置, 不同集群的CNI插件以及安全策略不同, 需要在创建app之前设置
+ *    Date: 2026/10/19
+ */
+
+// ClusterOptions 集群配置
+type ClusterOptions struct {
+	// MacAddressAnnotation CNI插件设置pod MAC地址的注解, 为空表示集群不支持 MACADDRESS
+	// 例如 kube-ovn: ovn.kubernetes.io/mac_address, calico: cni.projectcalico.org/hwAddr
+	MacAddressAnnotation string
+	// EnableUlimit 启用 ULIMIT, 需要集群允许容器添加 SYS_RESOURCE 能力并且镜像包含 prlimit(util-linux),
+	// 否则 postStart 钩子失败后容器会被kubelet重启; 未启用时 ULIMIT 在创建以及更新的校验时返回错误
+	EnableUlimit bool
+	// HistoryRetention 资源信息历史记录的保存时间, 默认: 1小时
+	HistoryRetention time.Duration
+	// HistoryResolution 资源信息历史记录的精度, 同一个精度周期内的采样取平均值, 默认: 15秒
+	HistoryResolution time.Duration
+	// MetricsSource 资源信息的来源: metrics-server, kubelet, 为空时在 Init 时自动检测(metrics.k8s.io 不存在时使用 kubelet)
+	MetricsSource string
+}
+
+// SetClusterOptions 设置集群配置, 监控数据的来源不支持时返回错误; Init 之后设置的来源从下一次采集开始生效
+func (manage *ManagerK8s) SetClusterOptions(opt ClusterOptions) error {
+	switch opt.MetricsSource {
+	case "", MetricsSourceMetricsServer, MetricsSourceKubelet:
+	default:
+		return fmt.Errorf("unsupported metrics source: %s, supported: %s, %s", opt.MetricsSource, MetricsSourceMetricsServer, MetricsSourceKubelet)
+	}
+	manage.options = opt
+	if manage.api != nil && opt.MetricsSource != "" {
+		manage.api.setMetricsSource(opt.MetricsSource)
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	logger "github.com/alecthomas/log4go"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sort"
+	"strconv"
+)
+
+/**
+ *    Description: 自动调度, NodeName为空时根据节点资源,app数量,主机端口以及节点标签选择目标节点
+ *    Date: 2026/10/19
+ */
+
+type (
+	// PlacementDecision 自动调度的结果以及依据
+	PlacementDecision struct {
+		NodeName   string
+		Reason     string
+		Candidates []PlacementCandidate // 按得分从高到低排序
+	}
+	// PlacementCandidate 候选节点的评估结果
+	PlacementCandidate struct {
+		NodeName string
+		Eligible bool     // 是否满足调度条件
+		Score    float64  // 得分, 越高越优先: 空闲CPU(40) + 空闲内存(40) + app数量(20)
+		FreeCpu  uint64   // 空闲CPU, 单位: 毫核
+		FreeMem  uint64   // 空闲内存, 单位: MB
+		AppCount int      // 已有app数量
+		Reasons  []string // 评估依据
+	}
+	// hostPortUsage 主机端口的占用信息
+	hostPortUsage struct {
+		node     string
+		app      string
+		port     int32
+		protocol string
+	}
+	// placementRequest 调度请求
+	placementRequest struct {
+		app          string
+		hostPorts    []hostPortUsage
+		nodeLabels   map[string]string
+		tolerations  []TolerationInfo
+		nodeAffinity []NodeAffinityInfo
+		exclude      string
+	}
+)
+
+// requestedHostPorts 请求需要占用的主机端口(主容器以及边车容器), host模式下占用容器端口, 非host模式下占用映射的主机端口
+func requestedHostPorts(info *CreateReqInfo) []hostPortUsage {
+	var ports []hostPortUsage
+	allPorts := info.Port
+	for _, sidecar := range info.Sidecars {
+		allPorts = append(allPorts, sidecar.Port...)
+	}
+	for _, port := range allPorts {
+		usage := hostPortUsage{node: info.NodeName, app: info.Name, port: int32(port.OuterPort), protocol: port.Protocol}
+		if info.HostNetwork {
+			usage.port = int32(port.InnerPort)
+		}
+		if usage.protocol == "" {
+			usage.protocol = string(corev1.ProtocolTCP)
+		}
+		if usage.port > 0 {
+			ports = append(ports, usage)
+		}
+	}
+	return ports
+}
+
+// listHostPorts 获取所有业务app占用的主机端口, key为节点名称
+func (api *k8sApi) listHostPorts() (map[string][]hostPortUsage, error) {
+	statefulSets, err := api.client.AppsV1().StatefulSets(api.appNamespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	usages := make(map[string][]hostPortUsage)
+	for _, item := range statefulSets.Items {
+		nodeName := item.Spec.Template.Spec.NodeSelector[labelHostname]
+		if nodeName == "" {
+			nodeName = item.Labels[labelNodeIP]
+		}
+		podSpec := item.Spec.Template.Spec
+		for _, container := range podSpec.Containers {
+			for _, port := range container.Ports {
+				usage := hostPortUsage{node: nodeName, app: item.Name, port: port.HostPort, protocol: string(port.Protocol)}
+				if podSpec.HostNetwork {
+					usage.port = port.ContainerPort
+				}
+				if usage.protocol == "" {
+					usage.protocol = string(corev1.ProtocolTCP)
+				}
+				if usage.port > 0 {
+					usages[nodeName] = append(usages[nodeName], usage)
+				}
+			}
+		}
+	}
+	return usages, nil
+}
+
+// nodeRequested 统计每个节点上所有未结束的pod申请的资源, key为节点名称
+func (api *k8sApi) nodeRequested() (map[string]ResourceInfo, error) {
+	pods, err := api.client.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{
+		FieldSelector: "status.phase!=Succeeded,status.phase!=Failed",
+	})
+	if err != nil {
+		return nil, err
+	}
+	requested := make(map[string]ResourceInfo)
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == "" {
+			continue
+		}
+		info := requested[pod.Spec.NodeName]
+		for _, container := range pod.Spec.Containers {
+			cpu := container.Resources.Requests[corev1.ResourceCPU]
+			memory := container.Resources.Requests[corev1.ResourceMemory]
+			info.Cpu += uint64(cpu.MilliValue())
+			info.Memory += uint64(memory.Value() / 1024 / 1024)
+		}
+		info.Pods++
+		requested[pod.Spec.NodeName] = info
+	}
+	return requested, nil
+}
+
+// SelectNode 为创建请求自动选择目标节点, 不会创建app
+func (manage *ManagerK8s) SelectNode(info *CreateReqInfo) (PlacementDecision, error) {
+	logger.Info("【容器: %s】select node 命令执行中...", info.Name)
+	req := placementRequest{app: info.Name, hostPorts: requestedHostPorts(info), nodeLabels: make(map[string]string), tolerations: info.Tolerations, nodeAffinity: info.NodeAffinity}
+	for _, label := range info.NodeLabel {
+		req.nodeLabels[label.Key] = label.Value
+	}
+	return manage.placeNode(req)
+}
+
+// checkNodeAffinity 指定节点时校验节点是否满足必须满足的亲和性规则, 优先满足的规则仅用于自动调度
+func (manage *ManagerK8s) checkNodeAffinity(info *CreateReqInfo) error {
+	var required []NodeAffinityInfo
+	for _, rule := range info.NodeAffinity {
+		if rule.Weight == 0 {
+			required = append(required, rule)
+		}
+	}
+	if len(required) == 0 {
+		return nil
+	}
+	node, err := manage.GetNode(info.NodeName)
+	if err != nil {
+		return err
+	}
+	for _, rule := range required {
+		if !matchNodeAffinity(rule, node.Labels) {
+			return logger.Warn("【容器: %s】节点: %s 不满足亲和性规则: %s %s %v", info.Name, info.NodeName, rule.Key, rule.Operator, rule.Values)
+		}
+	}
+	return nil
+}
+
+// StatefulSetCreateAuto 创建app, NodeName为空时自动选择目标节点并返回调度结果
+func (manage *ManagerK8s) StatefulSetCreateAuto(info *CreateReqInfo, isTry bool) (PlacementDecision, error) {
+	if info.NodeName != "" {
+		return PlacementDecision{NodeName: info.NodeName, Reason: "node specified by request"}, manage.StatefulSetCreate(info, isTry)
+	}
+	if err := manage.checkCreate(info); err != nil {
+		return PlacementDecision{}, err
+	}
+	return manage.statefulSetCreateAuto(info, isTry)
+}
+
+// statefulSetCreateAuto 自动选择节点后创建app, 调用前已完成 checkCreate; 选择节点时已过滤主机端口冲突以及不满足亲和性的节点
+func (manage *ManagerK8s) statefulSetCreateAuto(info *CreateReqInfo, isTry bool) (PlacementDecision, error) {
+	decision, err := manage.SelectNode(info)
+	if err != nil {
+		return decision, err
+	}
+	placed := *info
+	placed.NodeName = decision.NodeName
+	return decision, manage.statefulSetCreate(&placed, isTry)
+}
+
+// placeNode 对所有节点进行过滤以及打分, 返回得分最高的节点
+func (manage *ManagerK8s) placeNode(req placementRequest) (PlacementDecision, error) {
+	nodes, err := manage.ListNodes()
+	if err != nil {
+		return PlacementDecision{}, err
+	}
+	requested, err := manage.api.nodeRequested()
+	if err != nil {
+		return PlacementDecision{}, err
+	}
+	hostPorts, err := manage.api.listHostPorts()
+	if err != nil {
+		return PlacementDecision{}, err
+	}
+	decision := PlacementDecision{}
+	for _, node := range nodes {
+		if node.Name == req.exclude {
+			continue
+		}
+		decision.Candidates = append(decision.Candidates, scoreNode(node, requested[node.Name], hostPorts[node.Name], req))
+	}
+	sort.SliceStable(decision.Candidates, func(i, j int) bool {
+		if decision.Candidates[i].Eligible != decision.Candidates[j].Eligible {
+			return decision.Candidates[i].Eligible
+		}
+		return decision.Candidates[i].Score > decision.Candidates[j].Score
+	})
+	if len(decision.Candidates) == 0 || !decision.Candidates[0].Eligible {
+		decision.Reason = "no eligible node"
+		return decision, errors.New("no eligible node for placement")
+	}
+	best := decision.Candidates[0]
+	decision.NodeName = best.NodeName
+	decision.Reason = fmt.Sprintf("highest score %.2f: free cpu %dm, free memory %dMB, %d apps", best.Score, best.FreeCpu, best.FreeMem, best.AppCount)
+	logger.Info("【容器: %s】自动调度目标节点: %s, 依据: %s", req.app, decision.NodeName, decision.Reason)
+	return decision, nil
+}
+
+// scoreNode 评估单个节点
+func scoreNode(node NodeInfo, requested ResourceInfo, used []hostPortUsage, req placementRequest) PlacementCandidate {
+	candidate := PlacementCandidate{NodeName: node.Name, Eligible: true, AppCount: node.Total}
+	reject := func(reason string) {
+		candidate.Eligible = false
+		candidate.Reasons = append(candidate.Reasons, reason)
+	}
+	if !node.Ready {
+		reject("node is not ready")
+	}
+	if node.Unschedulable {
+		reject("node is cordoned")
+	}
+	for key, value := range req.nodeLabels {
+		if node.Labels[key] != value {
+			reject(fmt.Sprintf("node label %s=%s not matched", key, value))
+		}
+	}
+	if ok, reason := toleratesTaints(req.tolerations, node.Taints); !ok {
+		reject(reason)
+	}
+	var affinityScore float64
+	for _, rule := range req.nodeAffinity {
+		matched := matchNodeAffinity(rule, node.Labels)
+		if rule.Weight == 0 && !matched {
+			reject(fmt.Sprintf("node affinity %s %s %v not matched", rule.Key, rule.Operator, rule.Values))
+		} else if rule.Weight > 0 && matched {
+			affinityScore += float64(rule.Weight) / 10
+		}
+	}
+	for _, conflict := range hostPortConflicts(req.hostPorts, used, req.app) {
+		reject(conflict.Error())
+	}
+	if node.Allocatable.Cpu > requested.Cpu {
+		candidate.FreeCpu = node.Allocatable.Cpu - requested.Cpu
+	}
+	if node.Allocatable.Memory > requested.Memory {
+		candidate.FreeMem = node.Allocatable.Memory - requested.Memory
+	}
+	if candidate.FreeCpu == 0 || candidate.FreeMem == 0 {
+		reject("no allocatable cpu or memory left")
+	}
+	if node.Allocatable.Pods > 0 && requested.Pods >= node.Allocatable.Pods {
+		reject("pod capacity is full")
+	}
+	var cpuRatio, memRatio float64
+	if node.Allocatable.Cpu > 0 {
+		cpuRatio = float64(candidate.FreeCpu) / float64(node.Allocatable.Cpu)
+	}
+	if node.Allocatable.Memory > 0 {
+		memRatio = float64(candidate.FreeMem) / float64(node.Allocatable.Memory)
+	}
+	candidate.Score, _ = strconv.ParseFloat(fmt.Sprintf("%0.2f", cpuRatio*40+memRatio*40+20/float64(1+node.Total)+affinityScore), 64)
+	candidate.Reasons = append(candidate.Reasons, fmt.Sprintf("free cpu %.0f%%, free memory %.0f%%, %d apps", cpuRatio*100, memRatio*100, node.Total))
+	return candidate
+}
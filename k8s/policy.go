@@ -0,0 +1,301 @@
+package k8s
+
+import (
+	"fmt"
+	logger "github.com/alecthomas/log4go"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"path"
+	"strings"
+)
+
+/**
+ *    Description: 策略引擎, 创建以及更新app之前(包括dry-run)对请求进行审核
+ *    Date: 2026/10/19
+ */
+
+const (
+	PolicyCreate  = "create"
+	PolicyUpdate  = "update"
+	PolicyPrePull = "prepull" // 镜像预拉取, Info 为预拉取pod的容器(主容器为占位容器, 每个镜像一个init容器)
+)
+
+type (
+	// Policy 可插拔的审核策略, 返回空表示允许
+	Policy interface {
+		Name() string
+		Evaluate(req *PolicyRequest) []PolicyDenial
+	}
+	// policyValidator 策略实现该接口时, 设置策略时校验其配置
+	policyValidator interface {
+		Validate() error
+	}
+	// PolicyRequest 审核请求
+	PolicyRequest struct {
+		Operation string // create, update, prepull
+		Namespace string
+		Info      *CreateReqInfo
+	}
+	// PolicyDenial 拒绝原因
+	PolicyDenial struct {
+		Policy string
+		Field  string
+		Reason string
+	}
+	// PolicyDeniedError 请求被策略拒绝, 包含所有拒绝原因
+	PolicyDeniedError struct {
+		Operation string
+		Name      string
+		Denials   []PolicyDenial
+	}
+	// Permit 允许的app名称或者标签, 满足任意一个即可
+	Permit struct {
+		Apps   []string
+		Labels []LabelInfo
+	}
+	// RulePolicy 内置的规则策略, 未配置的规则不做限制
+	RulePolicy struct {
+		AllowedRegistries []string // 允许的镜像仓库, 例如: harbor.example.com, docker.io
+		AllowedHostPaths  []string // 允许挂载的主机路径前缀, 例如: /opt/data
+		Privileged        *Permit  // 允许使用特权模式的app, 添加危险的Linux能力, ULIMIT 以及设备挂载同样视为特权模式
+		HostNetwork       *Permit  // 允许使用host模式的app
+		MaxCpu            string   // 单个app最大的CPU申请以及限制, 例如: 4; 按pod合计: 主容器与边车容器之和, 与init容器的最大值取较大值, 每个容器都需要设置限制
+		MaxMemory         string   // 单个app最大的内存申请以及限制, 例如: 8Gi; 合计方式同 MaxCpu
+	}
+)
+
+func (e *PolicyDeniedError) Error() string {
+	reasons := make([]string, 0, len(e.Denials))
+	for _, denial := range e.Denials {
+		reasons = append(reasons, fmt.Sprintf("[%s] %s: %s", denial.Policy, denial.Field, denial.Reason))
+	}
+	return fmt.Sprintf("%s app %s denied by policy: %s", e.Operation, e.Name, strings.Join(reasons, "; "))
+}
+
+// dangerousCapabilities 等同于特权模式的Linux能力
+var dangerousCapabilities = []string{
+	"ALL", "SYS_ADMIN", "SYS_MODULE", "SYS_RAWIO", "SYS_PTRACE", "SYS_BOOT", "SYS_TIME", "SYS_RESOURCE",
+	"NET_ADMIN", "DAC_READ_SEARCH", "BPF", "PERFMON", "MAC_ADMIN", "MAC_OVERRIDE", "SYSLOG",
+}
+
+// SetPolicies 设置审核策略, 策略的配置无效时返回错误并保留原有的策略
+func (manage *ManagerK8s) SetPolicies(policies ...Policy) error {
+	for _, policy := range policies {
+		if validator, ok := policy.(policyValidator); ok {
+			if err := validator.Validate(); err != nil {
+				return logger.Warn("策略[%s]配置无效: %v", policy.Name(), err)
+			}
+		}
+	}
+	manage.policies = policies
+	return nil
+}
+
+// checkPolicies 执行所有的审核策略, 返回 *PolicyDeniedError
+func (manage *ManagerK8s) checkPolicies(operation string, info *CreateReqInfo) error {
+	req := &PolicyRequest{Operation: operation, Namespace: manage.appNamespace, Info: info}
+	var denials []PolicyDenial
+	for _, policy := range manage.policies {
+		denials = append(denials, policy.Evaluate(req)...)
+	}
+	if len(denials) == 0 {
+		return nil
+	}
+	err := &PolicyDeniedError{Operation: operation, Name: info.Name, Denials: denials}
+	logger.Warn("【容器: %s】%s container 策略审核未通过: %s", info.Name, operation, err)
+	return err
+}
+
+func (p *RulePolicy) Name() string {
+	return "rule"
+}
+
+// Validate 校验资源上限的配置
+func (p *RulePolicy) Validate() error {
+	_, _, err := p.maxQuantities()
+	return err
+}
+
+// maxQuantities 解析CPU以及内存的上限, 未配置时为nil
+func (p *RulePolicy) maxQuantities() (maxCpu, maxMemory *resource.Quantity, err error) {
+	parse := func(name, value string) (*resource.Quantity, error) {
+		if value == "" {
+			return nil, nil
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q: %v", name, value, err)
+		}
+		return &quantity, nil
+	}
+	if maxCpu, err = parse("MaxCpu", p.MaxCpu); err != nil {
+		return nil, nil, err
+	}
+	if maxMemory, err = parse("MaxMemory", p.MaxMemory); err != nil {
+		return nil, nil, err
+	}
+	return maxCpu, maxMemory, nil
+}
+
+func (p *RulePolicy) Evaluate(req *PolicyRequest) []PolicyDenial {
+	var denials []PolicyDenial
+	deny := func(field, reason string) {
+		denials = append(denials, PolicyDenial{Policy: p.Name(), Field: field, Reason: reason})
+	}
+	info := req.Info
+	for i, spec := range allContainerSpecs(info) {
+		prefix := containerFieldPath(info, i)
+		if len(p.AllowedRegistries) > 0 && !containsString(p.AllowedRegistries, imageRegistry(spec.Image)) {
+			deny(prefix+"Image", fmt.Sprintf("registry %s is not allowed", imageRegistry(spec.Image)))
+		}
+		if len(p.AllowedHostPaths) == 0 {
+			continue
+		}
+		for j, volume := range spec.Volume {
+			if !hostPathAllowed(p.AllowedHostPaths, volume.OuterPath) {
+				deny(fmt.Sprintf("%sVolume[%d].OuterPath", prefix, j), fmt.Sprintf("host path %s is not allowed", volume.OuterPath))
+			}
+		}
+	}
+	if len(p.AllowedHostPaths) > 0 {
+		for i, device := range info.Security.Device {
+			if !hostPathAllowed(p.AllowedHostPaths, device.HostPath) {
+				deny(fmt.Sprintf("Security.Device[%d].HostPath", i), fmt.Sprintf("host path %s is not allowed", device.HostPath))
+			}
+		}
+	}
+	if p.Privileged != nil && !p.Privileged.allow(info) {
+		for _, fieldName := range privilegedFields(info) {
+			deny(fieldName, "privileged mode is not allowed for this app")
+		}
+	}
+	if p.HostNetwork != nil && info.HostNetwork && !p.HostNetwork.allow(info) {
+		deny("HostNetwork", "host network is not allowed for this app")
+	}
+	maxCpu, maxMemory, err := p.maxQuantities()
+	if err != nil {
+		deny("Resource", err.Error())
+		return denials
+	}
+	// 限制的是pod的有效资源: 主容器与边车容器之和, 与init容器的最大值取较大值(init容器按顺序执行, 与其他容器不同时运行)
+	specs := allContainerSpecs(info)
+	specs[0].Resource = info.Resource
+	checkMax := func(max *resource.Quantity, fieldName string, required bool, value func(ResourceReqInfo) string) {
+		if max == nil {
+			return
+		}
+		var total, initMax resource.Quantity
+		for i, spec := range specs {
+			raw := value(spec.Resource)
+			if raw == "" {
+				if required {
+					deny(containerFieldPath(info, i)+"Resource."+fieldName, fmt.Sprintf("must be set, max %s", max.String()))
+				}
+				continue
+			}
+			quantity, err := resource.ParseQuantity(raw)
+			if err != nil {
+				continue
+			}
+			if i > 0 && i <= len(info.InitContainers) {
+				if quantity.Cmp(initMax) > 0 {
+					initMax = quantity
+				}
+				continue
+			}
+			total.Add(quantity)
+		}
+		if initMax.Cmp(total) > 0 {
+			total = initMax
+		}
+		if total.Cmp(*max) > 0 {
+			if len(specs) == 1 {
+				deny("Resource."+fieldName, fmt.Sprintf("%s exceeds max %s", value(info.Resource), max.String()))
+			} else {
+				deny("Resource."+fieldName, fmt.Sprintf("pod total %s (main, sidecar and init containers) exceeds max %s", total.String(), max.String()))
+			}
+		}
+	}
+	checkMax(maxCpu, "CpuLimit", true, func(r ResourceReqInfo) string { return r.CpuLimit })
+	checkMax(maxMemory, "MemLimit", true, func(r ResourceReqInfo) string { return r.MemLimit })
+	checkMax(maxCpu, "CpuRequest", false, func(r ResourceReqInfo) string { return r.CpuRequest })
+	checkMax(maxMemory, "MemRequest", false, func(r ResourceReqInfo) string { return r.MemRequest })
+	return denials
+}
+
+// containerFieldPath allContainerSpecs 中第i个容器的字段前缀, 主容器为空
+func containerFieldPath(info *CreateReqInfo, i int) string {
+	switch {
+	case i == 0:
+		return ""
+	case i <= len(info.InitContainers):
+		return fmt.Sprintf("InitContainers[%d].", i-1)
+	default:
+		return fmt.Sprintf("Sidecars[%d].", i-1-len(info.InitContainers))
+	}
+}
+
+// allow app名称或者标签满足任意一个即允许
+func (p *Permit) allow(info *CreateReqInfo) bool {
+	if containsString(p.Apps, info.Name) {
+		return true
+	}
+	for _, permit := range p.Labels {
+		for _, label := range info.Label {
+			if label.Key == permit.Key && label.Value == permit.Value {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// privilegedFields 返回等同于特权模式的字段: PRIVILEGED 环境变量, 危险的Linux能力, ULIMIT(添加 SYS_RESOURCE)以及设备挂载
+func privilegedFields(info *CreateReqInfo) []string {
+	var fields []string
+	for i, env := range info.Env {
+		if env.Key == ENV_PRIVILEGED && (env.Value == "true" || env.Value == "1") || env.Key == ENV_ULIMIT_NAME {
+			fields = append(fields, fmt.Sprintf("Env[%d].%s", i, env.Key))
+		}
+	}
+	for i, name := range info.Security.CapAdd {
+		if containsString(dangerousCapabilities, string(normalizeCapability(name))) {
+			fields = append(fields, fmt.Sprintf("Security.CapAdd[%d]", i))
+		}
+	}
+	for i := range info.Security.Device {
+		fields = append(fields, fmt.Sprintf("Security.Device[%d]", i))
+	}
+	return fields
+}
+
+// imageRegistry 获取镜像的仓库地址, 未指定仓库时为 docker.io
+func imageRegistry(image string) string {
+	if i := strings.Index(image, "/"); i > 0 {
+		domain := image[:i]
+		if strings.ContainsAny(domain, ".:") || domain == "localhost" {
+			return domain
+		}
+	}
+	return "docker.io"
+}
+
+// hostPathAllowed 主机路径是否在允许的前缀下
+func hostPathAllowed(prefixes []string, hostPath string) bool {
+	hostPath = path.Clean(hostPath)
+	for _, prefix := range prefixes {
+		prefix = path.Clean(prefix)
+		if prefix == "/" || hostPath == prefix || strings.HasPrefix(hostPath, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
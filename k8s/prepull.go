@@ -0,0 +1,328 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	logger "github.com/alecthomas/log4go"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strings"
+	"time"
+)
+
+/**
+ *    Description: 镜像预拉取, 通过临时的DaemonSet(按节点名称固定)在目标节点上提前拉取镜像, 缩短创建以及更新app时的等待时间
+ *    Date: 2026/10/19
+ */
+
+const (
+	defaultPrePullTimeout = 10 * time.Minute
+	labelPrePull          = "prepull"
+	excludePrePull        = "!" + labelPrePull // 排除预拉取pod的标签选择器
+)
+
+// prePullResource 预拉取容器的资源限制, 同时用于策略审核中的资源上限校验
+var prePullResource = ResourceReqInfo{CpuLimit: "100m", MemLimit: "64Mi"}
+
+// imagePullingReasons 镜像仍在拉取或者拉取失败的等待原因, 其他的等待原因(例如镜像中没有shell导致的启动失败)说明镜像已经拉取完成
+var imagePullingReasons = map[string]bool{
+	"": true, "ContainerCreating": true, "PodInitializing": true,
+	"ErrImagePull": true, "ImagePullBackOff": true, "InvalidImageName": true, "ErrImageNeverPull": true, "RegistryUnavailable": true,
+}
+
+// imagePullFailedReasons 重试也不会成功的拉取失败原因, 无需等待超时
+var imagePullFailedReasons = map[string]bool{"InvalidImageName": true, "ErrImageNeverPull": true}
+
+type (
+	// PrePullOptions 镜像预拉取参数
+	PrePullOptions struct {
+		Nodes       []string      // 目标节点
+		PullSecrets []string      // 镜像仓库认证的Secret名称
+		Timeout     time.Duration // 超时时间, 默认: 10分钟
+		Progress    func(p PrePullResult)
+	}
+	// PrePullResult 单个节点的预拉取结果
+	PrePullResult struct {
+		Node     string
+		Image    string
+		Done     bool
+		Error    string
+		Duration time.Duration
+	}
+)
+
+// newPrePullDaemonSet 生成预拉取的DaemonSet, 每个镜像一个容器并行拉取, 镜像拉取完成后即使命令执行失败(例如镜像中没有shell)也不影响结果以及其他镜像
+func newPrePullDaemonSet(name, namespace string, images []string, opt PrePullOptions) *v1.DaemonSet {
+	labels := map[string]string{labelPrePull: name}
+	spec := corev1.PodSpec{
+		Affinity: &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{Key: labelHostname, Operator: corev1.NodeSelectorOpIn, Values: opt.Nodes}},
+			}}},
+		}},
+		Tolerations: []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+	}
+	for i, image := range images {
+		spec.Containers = append(spec.Containers, corev1.Container{
+			Name:            fmt.Sprintf("pull-%d", i),
+			Image:           image,
+			Command:         []string{"/bin/sh", "-c", "exit 0"},
+			ImagePullPolicy: corev1.PullIfNotPresent,
+			Resources:       resourceRequirements(prePullResource),
+		})
+	}
+	for _, secret := range opt.PullSecrets {
+		spec.ImagePullSecrets = append(spec.ImagePullSecrets, corev1.LocalObjectReference{Name: secret})
+	}
+	return &v1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec: v1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{ObjectMeta: metav1.ObjectMeta{Labels: labels}, Spec: spec},
+		},
+	}
+}
+
+// imagePulled 判断容器的镜像是否拉取完成, 返回是否完成, 拉取失败的原因以及是否无需再等待
+func imagePulled(status corev1.ContainerStatus) (bool, string, bool) {
+	if status.ImageID != "" || status.State.Running != nil || status.State.Terminated != nil || status.LastTerminationState.Terminated != nil {
+		return true, "", false
+	}
+	if status.State.Waiting != nil {
+		reason := status.State.Waiting.Reason
+		if !imagePullingReasons[reason] {
+			return true, "", false
+		}
+		if status.State.Waiting.Message != "" {
+			return false, reason + ": " + status.State.Waiting.Message, imagePullFailedReasons[reason]
+		}
+		return false, reason, imagePullFailedReasons[reason]
+	}
+	return false, "", false
+}
+
+func (api *k8sApi) daemonSetCreate(namespace string, daemonSet *v1.DaemonSet) error {
+	_, err := api.client.AppsV1().DaemonSets(namespace).Create(context.Background(), daemonSet, metav1.CreateOptions{})
+	return err
+}
+
+func (api *k8sApi) daemonSetDelete(name, namespace string) error {
+	propagation := metav1.DeletePropagationBackground
+	return api.client.AppsV1().DaemonSets(namespace).Delete(context.Background(), name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+}
+
+func (api *k8sApi) listPodsByLabel(namespace, selector string) ([]corev1.Pod, error) {
+	pods, err := api.client.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
+// trackPrePullPod 记录预拉取的pod名称, 返回是否为预拉取的pod
+func (api *k8sApi) trackPrePullPod(pod *corev1.Pod, deleted bool) bool {
+	if _, ok := pod.Labels[labelPrePull]; !ok {
+		return false
+	}
+	if deleted {
+		api.prePullPods.Delete(pod.Name)
+	} else {
+		api.prePullPods.Store(pod.Name, true)
+	}
+	return true
+}
+
+// isPrePullPod 是否为预拉取的pod, kubelet的资源信息中没有标签, 根据记录的名称判断
+func (api *k8sApi) isPrePullPod(name string) bool {
+	_, ok := api.prePullPods.Load(name)
+	return ok
+}
+
+// prePullReqInfo 预拉取pod对应的创建请求, 用于镜像地址校验以及策略审核, 第一个镜像为主容器, 其他镜像为边车容器
+func prePullReqInfo(name string, images []string, opt PrePullOptions) *CreateReqInfo {
+	info := &CreateReqInfo{Name: name, Image: images[0], ImagePullSecrets: opt.PullSecrets, Resource: prePullResource}
+	for i, image := range images[1:] {
+		info.Sidecars = append(info.Sidecars, ContainerSpecInfo{Name: fmt.Sprintf("pull-%d", i+1), Image: image, Resource: prePullResource})
+	}
+	return info
+}
+
+// prePullNodes 去除重复的节点并校验节点是否存在, 不存在的节点不会运行预拉取的pod, 只能等到超时
+func (manage *ManagerK8s) prePullNodes(nodes []string) ([]string, error) {
+	seen := make(map[string]bool, len(nodes))
+	var unique, unknown []string
+	for _, node := range nodes {
+		if seen[node] {
+			continue
+		}
+		seen[node] = true
+		if _, ok := manage.nodeCache.getCacheNodeInfo(node); !ok {
+			if _, err := manage.api.getNode(node); err != nil {
+				if !apierrors.IsNotFound(err) {
+					return nil, err
+				}
+				unknown = append(unknown, node)
+				continue
+			}
+		}
+		unique = append(unique, node)
+	}
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("pre-pull nodes %v not found", unknown)
+	}
+	return unique, nil
+}
+
+// ImagePrePull 在目标节点上预拉取镜像, 等待所有节点完成或者超时后删除临时的DaemonSet, 镜像需要通过策略审核
+func (manage *ManagerK8s) ImagePrePull(images []string, opt PrePullOptions) ([]PrePullResult, error) {
+	logger.Info("【镜像: %v】image pre-pull 命令执行中... 目标节点: %v", images, opt.Nodes)
+	if len(images) == 0 || len(opt.Nodes) == 0 {
+		return nil, errors.New("pre-pull images and nodes must not be empty")
+	}
+	if opt.Timeout <= 0 {
+		opt.Timeout = defaultPrePullTimeout
+	}
+	nodes, err := manage.prePullNodes(opt.Nodes)
+	if err != nil {
+		return nil, err
+	}
+	opt.Nodes = nodes
+	name := fmt.Sprintf("prepull-%d", time.Now().UnixNano())
+	req := prePullReqInfo(name, images, opt)
+	for i, spec := range allContainerSpecs(req) {
+		if !imageReferenceRegexp.MatchString(spec.Image) {
+			return nil, fmt.Errorf("%sImage: invalid image reference %q", containerFieldPath(req, i), spec.Image)
+		}
+	}
+	if err := manage.checkPolicies(PolicyPrePull, req); err != nil {
+		return nil, err
+	}
+	if err := manage.api.daemonSetCreate(manage.appNamespace, newPrePullDaemonSet(name, manage.appNamespace, images, opt)); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := manage.api.daemonSetDelete(name, manage.appNamespace); err != nil {
+			logger.Warn("【镜像: %v】删除预拉取DaemonSet[%s]失败: %v", images, name, err)
+		}
+	}()
+	start := time.Now()
+	results := make(map[string]*PrePullResult, len(opt.Nodes))
+	for _, node := range opt.Nodes {
+		results[node] = &PrePullResult{Node: node, Image: fmt.Sprint(images)}
+	}
+	finish := func(result *PrePullResult) {
+		result.Duration = time.Since(start)
+		if opt.Progress != nil {
+			opt.Progress(*result)
+		}
+	}
+	finished := make(map[string]bool, len(opt.Nodes))
+	for pending := len(opt.Nodes); pending > 0; {
+		pods, err := manage.api.listPodsByLabel(manage.appNamespace, labelPrePull+"="+name)
+		if err != nil {
+			return prePullResults(opt.Nodes, results), err
+		}
+		for _, pod := range pods {
+			result, ok := results[pod.Spec.NodeName]
+			if !ok || finished[result.Node] {
+				continue
+			}
+			done, failed := len(pod.Status.ContainerStatuses) == len(images), false
+			var reasons []string
+			for _, status := range pod.Status.ContainerStatuses {
+				pulled, reason, terminal := imagePulled(status)
+				if pulled {
+					continue
+				}
+				done = false
+				failed = failed || terminal
+				if reason != "" {
+					reasons = append(reasons, status.Image+": "+reason)
+				}
+			}
+			result.Error = strings.Join(reasons, "; ")
+			if done || failed {
+				result.Done, finished[result.Node] = done, true
+				pending--
+				finish(result)
+				if done {
+					logger.Info("【镜像: %v】节点: %s 预拉取完成, 耗时: %v", images, result.Node, result.Duration)
+				} else {
+					logger.Warn("【镜像: %v】节点: %s 预拉取失败: %s", images, result.Node, result.Error)
+				}
+			}
+		}
+		if pending == 0 {
+			break
+		}
+		if time.Since(start) > opt.Timeout {
+			for _, result := range results {
+				if !finished[result.Node] {
+					if result.Error == "" {
+						result.Error = "timeout"
+					}
+					finish(result)
+				}
+			}
+			return prePullResults(opt.Nodes, results), fmt.Errorf("pre-pull images %v timeout, %d/%d nodes not finished", images, pending, len(opt.Nodes))
+		}
+		time.Sleep(2 * time.Second)
+	}
+	for _, result := range results {
+		if !result.Done {
+			return prePullResults(opt.Nodes, results), fmt.Errorf("pre-pull images %v failed on node %s: %s", images, result.Node, result.Error)
+		}
+	}
+	return prePullResults(opt.Nodes, results), nil
+}
+
+// StatefulSetUpdatePrePull 先在app所在节点上预拉取所有容器的镜像, 完成后再更新app, 缩短容器重启的时间;
+// 预拉取之前完成更新请求的校验以及策略审核, 未通过时不会拉取镜像
+func (manage *ManagerK8s) StatefulSetUpdatePrePull(info *CreateReqInfo, opt PrePullOptions, isTry bool) ([]PrePullResult, error) {
+	if err := info.Validate(); err != nil {
+		logger.Warn("【容器: %s】update container 参数校验失败: %v", info.Name, err)
+		return nil, err
+	}
+	if err := manage.validateUlimitSupport(info); err != nil {
+		return nil, logger.Warn("【容器: %s】update container 参数校验失败: %v", info.Name, err)
+	}
+	if err := manage.checkPolicies(PolicyUpdate, info); err != nil {
+		return nil, err
+	}
+	var results []PrePullResult
+	if !isTry {
+		nodeName := info.NodeName
+		if nodeName == "" {
+			var err error
+			if nodeName, err = manage.api.statefulSetNodeName(info.Name, manage.appNamespace); err != nil {
+				return nil, err
+			}
+		}
+		var images []string
+		for _, spec := range allContainerSpecs(info) {
+			images = append(images, spec.Image)
+		}
+		if len(opt.Nodes) == 0 {
+			opt.Nodes = []string{nodeName}
+		}
+		if len(opt.PullSecrets) == 0 {
+			opt.PullSecrets = info.ImagePullSecrets
+		}
+		var err error
+		if results, err = manage.ImagePrePull(images, opt); err != nil {
+			return results, err
+		}
+	}
+	return results, manage.StatefulSetUpdate(info, isTry)
+}
+
+func prePullResults(nodes []string, results map[string]*PrePullResult) []PrePullResult {
+	list := make([]PrePullResult, 0, len(nodes))
+	for _, node := range nodes {
+		list = append(list, *results[node])
+	}
+	return list
+}
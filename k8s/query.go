@@ -0,0 +1,167 @@
+package k8s
+
+import (
+	"fmt"
+	logger "github.com/alecthomas/log4go"
+	"sort"
+	"strings"
+)
+
+/**
+ *    Description: 资源信息缓存的通用查询: 过滤, 按任意字段排序以及分页
+ *    Date: 2026/10/19
+ */
+
+const (
+	StatSortCpuRatio = "cpu_ratio"
+	StatSortCpuUsed  = "cpu_used"
+	StatSortMemRatio = "mem_ratio"
+	StatSortMemUsed  = "mem_used"
+	StatSortNet      = "net"  // 网络速率(接收+发送)
+	StatSortDisk     = "disk" // 磁盘占用(容器可写层+卷)
+	StatSortRestarts = "restarts"
+	StatSortName     = "name"
+	StatSortStartAt  = "start_time"
+)
+
+type (
+	// StatQuery 查询条件, 过滤条件为空表示不过滤
+	StatQuery struct {
+		Namespace string // 为空时查询业务空间
+		SortBy    string // 默认: name
+		Desc      bool
+		Labels    map[string]string // 需要全部匹配的pod标签
+		Node      string            // 节点名称或者节点地址
+		Status    string            // pod状态, 例如: Running, Pending
+		Top       int               // 排序后只保留前N个, 0表示不限制
+		Offset    int
+		Limit     int // 0表示不限制
+	}
+	// StatQueryItem app的资源信息以及容器信息
+	StatQueryItem struct {
+		Stat StatInfo
+		Info ContainerInfo
+	}
+	// StatQueryResult 查询结果, Total 为分页前的总数
+	StatQueryResult struct {
+		Items []StatQueryItem
+		Total int
+	}
+)
+
+// statLess 各排序字段的比较函数(升序)
+var statLess = map[string]func(a, b *StatQueryItem) bool{
+	StatSortCpuRatio: func(a, b *StatQueryItem) bool { return a.Stat.CpuLoad.Ratio < b.Stat.CpuLoad.Ratio },
+	StatSortCpuUsed:  func(a, b *StatQueryItem) bool { return a.Stat.CpuLoad.Used < b.Stat.CpuLoad.Used },
+	StatSortMemRatio: func(a, b *StatQueryItem) bool { return a.Stat.MemLoad.Ratio < b.Stat.MemLoad.Ratio },
+	StatSortMemUsed:  func(a, b *StatQueryItem) bool { return a.Stat.MemLoad.Used < b.Stat.MemLoad.Used },
+	StatSortNet: func(a, b *StatQueryItem) bool {
+		return a.Stat.NetLoad.RxRate+a.Stat.NetLoad.TxRate < b.Stat.NetLoad.RxRate+b.Stat.NetLoad.TxRate
+	},
+	StatSortDisk: func(a, b *StatQueryItem) bool {
+		return a.Stat.DiskLoad.RootfsUsed+a.Stat.DiskLoad.VolumeUsed < b.Stat.DiskLoad.RootfsUsed+b.Stat.DiskLoad.VolumeUsed
+	},
+	StatSortRestarts: func(a, b *StatQueryItem) bool { return a.Info.ReStartCount < b.Info.ReStartCount },
+	StatSortName:     func(a, b *StatQueryItem) bool { return a.Stat.Name < b.Stat.Name },
+	StatSortStartAt:  func(a, b *StatQueryItem) bool { return a.Info.NewStartAt.Before(b.Info.NewStartAt) },
+}
+
+// match 是否满足过滤条件
+func (query *StatQuery) match(info ContainerInfo) bool {
+	if query.Node != "" && query.Node != info.NodeName && query.Node != info.HostIP {
+		return false
+	}
+	if query.Status != "" && query.Status != info.Status {
+		return false
+	}
+	for key, value := range query.Labels {
+		if info.Labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// queryItems 获取空间下所有app的资源信息以及容器信息, 没有资源信息的app资源占用为0
+func (cache *ContainerCache) queryItems(namespace string) []StatQueryItem {
+	items := make([]StatQueryItem, 0)
+	cache.cache.Range(func(key, value any) bool {
+		monitor, ok := value.(ContainerMonitor)
+		if !ok || !strings.HasSuffix(key.(string), "_"+namespace) {
+			return true
+		}
+		name := strings.TrimSuffix(key.(string), "_"+namespace)
+		item := StatQueryItem{Stat: StatInfo{Name: name}, Info: ContainerInfo{Name: name}}
+		if monitor.statInfo != nil {
+			item.Stat = *monitor.statInfo
+		}
+		if monitor.containerInfo != nil {
+			item.Info = *monitor.containerInfo
+		}
+		items = append(items, item)
+		return true
+	})
+	return items
+}
+
+// QueryStats 按条件查询空间下app的资源信息, 相同排序值按名称排序
+func (manage *ManagerK8s) QueryStats(query StatQuery) (StatQueryResult, error) {
+	if query.SortBy == "" {
+		query.SortBy = StatSortName
+	}
+	less, ok := statLess[query.SortBy]
+	if !ok {
+		return StatQueryResult{}, fmt.Errorf("unsupported sort key %q", query.SortBy)
+	}
+	if query.Offset < 0 || query.Limit < 0 || query.Top < 0 {
+		return StatQueryResult{}, fmt.Errorf("offset, limit and top must not be negative")
+	}
+	if query.Namespace == "" {
+		query.Namespace = manage.appNamespace
+	}
+	var items []StatQueryItem
+	for _, item := range manage.containerCache.queryItems(query.Namespace) {
+		if query.match(item.Info) {
+			items = append(items, item)
+		}
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		a, b := &items[i], &items[j]
+		if query.Desc {
+			a, b = b, a
+		}
+		if less(a, b) {
+			return true
+		}
+		if less(b, a) {
+			return false
+		}
+		return items[i].Stat.Name < items[j].Stat.Name
+	})
+	if query.Top > 0 && len(items) > query.Top {
+		items = items[:query.Top]
+	}
+	result := StatQueryResult{Total: len(items)}
+	if query.Offset >= len(items) {
+		return result, nil
+	}
+	items = items[query.Offset:]
+	if query.Limit > 0 && len(items) > query.Limit {
+		items = items[:query.Limit]
+	}
+	result.Items = items
+	return result, nil
+}
+
+// queryStatInfos 按单个字段排序返回空间下所有app的资源信息, 兼容原有的 GetAllStatInfoOfSortBy* 接口
+func (manage *ManagerK8s) queryStatInfos(sortBy string, desc bool, namespace string) []*StatInfo {
+	result, err := manage.QueryStats(StatQuery{Namespace: namespace, SortBy: sortBy, Desc: desc})
+	if err != nil {
+		logger.Warn("【空间: %s】 query stats by %s 失败: %v", namespace, sortBy, err)
+	}
+	stats := make([]*StatInfo, 0, len(result.Items))
+	for i := range result.Items {
+		stats = append(stats, &result.Items[i].Stat)
+	}
+	return stats
+}
@@ -0,0 +1,149 @@
+package k8s
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	logger "github.com/alecthomas/log4go"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+/**
+ *    Description: 私有镜像仓库的认证信息, 以 docker-registry 类型的Secret保存, 创建app时通过 ImagePullSecrets 引用
+ *    Date: 2026/10/19
+ */
+
+var pullPolicies = []string{string(corev1.PullAlways), string(corev1.PullIfNotPresent), string(corev1.PullNever)}
+
+// RegistryCredential 镜像仓库认证信息
+type RegistryCredential struct {
+	Name      string // Secret名称
+	Namespace string // 为空时使用app的namespace
+	Server    string // 仓库地址, 例如: harbor.example.com
+	Username  string
+	Password  string
+	Email     string
+}
+
+// dockerConfigJSON 生成 .dockerconfigjson 的内容
+func (cred *RegistryCredential) dockerConfigJSON() ([]byte, error) {
+	auth := map[string]string{
+		"username": cred.Username,
+		"password": cred.Password,
+		"auth":     base64.StdEncoding.EncodeToString([]byte(cred.Username + ":" + cred.Password)),
+	}
+	if cred.Email != "" {
+		auth["email"] = cred.Email
+	}
+	return json.Marshal(map[string]map[string]map[string]string{"auths": {cred.Server: auth}})
+}
+
+func (cred *RegistryCredential) validate() error {
+	var allErrs field.ErrorList
+	for _, msg := range validation.IsDNS1123Subdomain(cred.Name) {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("Name"), cred.Name, msg))
+	}
+	if cred.Server == "" {
+		allErrs = append(allErrs, field.Required(field.NewPath("Server"), ""))
+	}
+	if cred.Username == "" {
+		allErrs = append(allErrs, field.Required(field.NewPath("Username"), ""))
+	}
+	if cred.Password == "" {
+		allErrs = append(allErrs, field.Required(field.NewPath("Password"), ""))
+	}
+	return allErrs.ToAggregate()
+}
+
+// secretApply 创建Secret, 已经存在时更新
+func (api *k8sApi) secretApply(namespace string, secret *corev1.Secret, isTry ...bool) error {
+	var dryRun []string
+	if len(isTry) > 0 && isTry[0] {
+		dryRun = []string{"All"}
+	}
+	_, err := api.client.CoreV1().Secrets(namespace).Create(context.Background(), secret, metav1.CreateOptions{DryRun: dryRun})
+	if !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	existing, err := api.client.CoreV1().Secrets(namespace).Get(context.TODO(), secret.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if existing.Type != secret.Type {
+		return fmt.Errorf("secret %s/%s already exists with type %s", namespace, secret.Name, existing.Type)
+	}
+	existing.Data = secret.Data
+	_, err = api.client.CoreV1().Secrets(namespace).Update(context.Background(), existing, metav1.UpdateOptions{DryRun: dryRun})
+	return err
+}
+
+func (api *k8sApi) secretDelete(name, namespace string, isTry ...bool) error {
+	if len(isTry) > 0 && isTry[0] {
+		return api.client.CoreV1().Secrets(namespace).Delete(context.Background(), name, metav1.DeleteOptions{DryRun: []string{"All"}})
+	} else {
+		return api.client.CoreV1().Secrets(namespace).Delete(context.Background(), name, metav1.DeleteOptions{})
+	}
+}
+
+// RegistryCredentialApply 创建或者更新镜像仓库的认证信息
+func (manage *ManagerK8s) RegistryCredentialApply(cred *RegistryCredential, isTry bool) error {
+	logger.Info("【镜像仓库: %s】apply registry credential[%s] 命令执行中...", cred.Server, cred.Name)
+	if err := cred.validate(); err != nil {
+		return err
+	}
+	data, err := cred.dockerConfigJSON()
+	if err != nil {
+		return err
+	}
+	namespace := cred.Namespace
+	if namespace == "" {
+		namespace = manage.appNamespace
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: cred.Name, Namespace: namespace},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data:       map[string][]byte{corev1.DockerConfigJsonKey: data},
+	}
+	return manage.api.secretApply(namespace, secret, isTry)
+}
+
+// RegistryCredentialDelete 删除镜像仓库的认证信息, namespace为空时使用app的namespace
+func (manage *ManagerK8s) RegistryCredentialDelete(name, namespace string, isTry bool) error {
+	logger.Info("【镜像仓库认证: %s】delete registry credential 命令执行中...", name)
+	if namespace == "" {
+		namespace = manage.appNamespace
+	}
+	return manage.api.secretDelete(name, namespace, isTry)
+}
+
+// applyImagePull 设置镜像拉取的认证以及拉取策略, 拉取策略作用于所有容器
+func applyImagePull(createInfo *ContainerCreateInfo, info *CreateReqInfo) {
+	for _, name := range info.ImagePullSecrets {
+		createInfo.ImagePullSecrets = append(createInfo.ImagePullSecrets, corev1.LocalObjectReference{Name: name})
+	}
+	createInfo.ImagePullPolicy = corev1.PullPolicy(info.ImagePullPolicy)
+	for i := range createInfo.InitContainers {
+		createInfo.InitContainers[i].ImagePullPolicy = createInfo.ImagePullPolicy
+	}
+	for i := range createInfo.Sidecars {
+		createInfo.Sidecars[i].ImagePullPolicy = createInfo.ImagePullPolicy
+	}
+}
+
+func validateImagePull(info *CreateReqInfo) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, name := range info.ImagePullSecrets {
+		for _, msg := range validation.IsDNS1123Subdomain(name) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("ImagePullSecrets").Index(i), name, msg))
+		}
+	}
+	if info.ImagePullPolicy != "" && !containsString(pullPolicies, info.ImagePullPolicy) {
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("ImagePullPolicy"), info.ImagePullPolicy, pullPolicies))
+	}
+	return allErrs
+}
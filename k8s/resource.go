@@ -0,0 +1,61 @@
+package k8s
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+/**
+ *    Description: app的资源申请以及限制
+ *    Date: 2026/10/19
+ */
+
+// resourceRequirements 转换为k8s的资源配置, 未设置的资源不做申请以及限制
+func resourceRequirements(info ResourceReqInfo) corev1.ResourceRequirements {
+	requirements := corev1.ResourceRequirements{}
+	set := func(list *corev1.ResourceList, name corev1.ResourceName, value string) {
+		if value == "" {
+			return
+		}
+		if *list == nil {
+			*list = make(corev1.ResourceList)
+		}
+		(*list)[name] = resource.MustParse(value)
+	}
+	set(&requirements.Requests, corev1.ResourceCPU, info.CpuRequest)
+	set(&requirements.Requests, corev1.ResourceMemory, info.MemRequest)
+	set(&requirements.Limits, corev1.ResourceCPU, info.CpuLimit)
+	set(&requirements.Limits, corev1.ResourceMemory, info.MemLimit)
+	return requirements
+}
+
+func validateResource(info ResourceReqInfo, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	quantities := make(map[string]resource.Quantity)
+	names := []string{"CpuRequest", "CpuLimit", "MemRequest", "MemLimit"}
+	for i, value := range []string{info.CpuRequest, info.CpuLimit, info.MemRequest, info.MemLimit} {
+		name := names[i]
+		if value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(name), value, err.Error()))
+			continue
+		}
+		if quantity.Sign() < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(name), value, "must be greater than or equal to 0"))
+		}
+		quantities[name] = quantity
+	}
+	for _, pair := range [][2]string{{"CpuRequest", "CpuLimit"}, {"MemRequest", "MemLimit"}} {
+		request, limit := pair[0], pair[1]
+		requestQuantity, ok1 := quantities[request]
+		limitQuantity, ok2 := quantities[limit]
+		if ok1 && ok2 && requestQuantity.Cmp(limitQuantity) > 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(request), requestQuantity.String(), "must be less than or equal to "+limit))
+		}
+	}
+	return allErrs
+}
@@ -0,0 +1,228 @@
+package k8s
+
+import (
+	"encoding/json"
+	"fmt"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"net"
+	"strconv"
+	"strings"
+)
+
+/**
+ *    Description: 调度控制: 污点容忍, 节点亲和性, 优先级, DNS配置以及hosts配置
+ *    Date: 2026/10/19
+ */
+
+// annotationNodeAffinity StatefulSet上保存节点亲和性的注解, 用于导出以及drain迁移时选择目标节点
+const annotationNodeAffinity = "k8s-core-components/node-affinity"
+
+var (
+	tolerationOperators = []string{string(corev1.TolerationOpEqual), string(corev1.TolerationOpExists)}
+	taintEffects        = []string{string(corev1.TaintEffectNoSchedule), string(corev1.TaintEffectPreferNoSchedule), string(corev1.TaintEffectNoExecute)}
+	affinityOperators   = []string{string(corev1.NodeSelectorOpIn), string(corev1.NodeSelectorOpNotIn), string(corev1.NodeSelectorOpExists), string(corev1.NodeSelectorOpDoesNotExist), string(corev1.NodeSelectorOpGt), string(corev1.NodeSelectorOpLt)}
+	dnsPolicies         = []string{string(corev1.DNSClusterFirst), string(corev1.DNSClusterFirstWithHostNet), string(corev1.DNSDefault), string(corev1.DNSNone)}
+)
+
+// applyScheduling 将调度控制转换到创建信息中, pod固定在 NodeName 节点上,
+// 节点亲和性仅用于自动调度选择节点以及校验指定的节点, 不写入pod的配置, 保存在StatefulSet的注解中
+func applyScheduling(createInfo *ContainerCreateInfo, info *CreateReqInfo) {
+	if len(info.NodeAffinity) > 0 {
+		data, _ := json.Marshal(convertSlice(info.NodeAffinity, func(item NodeAffinityInfo) nodeAffinitySpec { return nodeAffinitySpec(item) }))
+		if createInfo.AppAnnotations == nil {
+			createInfo.AppAnnotations = make(map[string]string)
+		}
+		createInfo.AppAnnotations[annotationNodeAffinity] = string(data)
+	}
+	for _, toleration := range info.Tolerations {
+		createInfo.Tolerations = append(createInfo.Tolerations, corev1.Toleration{
+			Key:               toleration.Key,
+			Operator:          corev1.TolerationOperator(toleration.Operator),
+			Value:             toleration.Value,
+			Effect:            corev1.TaintEffect(toleration.Effect),
+			TolerationSeconds: toleration.Seconds,
+		})
+	}
+	createInfo.PriorityClassName = info.PriorityClassName
+	if info.DNS != nil {
+		createInfo.DNSPolicy = corev1.DNSPolicy(info.DNS.Policy)
+		if len(info.DNS.Nameservers) > 0 || len(info.DNS.Searches) > 0 || len(info.DNS.Options) > 0 {
+			createInfo.DNSConfig = &corev1.PodDNSConfig{Nameservers: info.DNS.Nameservers, Searches: info.DNS.Searches}
+			for _, option := range info.DNS.Options {
+				name, value, ok := strings.Cut(option, ":")
+				dnsOption := corev1.PodDNSConfigOption{Name: name}
+				if ok {
+					dnsOption.Value = &value
+				}
+				createInfo.DNSConfig.Options = append(createInfo.DNSConfig.Options, dnsOption)
+			}
+		}
+	}
+	for _, alias := range info.HostAliases {
+		createInfo.HostAliases = append(createInfo.HostAliases, corev1.HostAlias{IP: alias.IP, Hostnames: alias.Hostnames})
+	}
+}
+
+// savedNodeAffinity 读取StatefulSet注解中保存的节点亲和性
+func savedNodeAffinity(annotations map[string]string) ([]NodeAffinityInfo, error) {
+	data, ok := annotations[annotationNodeAffinity]
+	if !ok {
+		return nil, nil
+	}
+	var rules []nodeAffinitySpec
+	if err := json.Unmarshal([]byte(data), &rules); err != nil {
+		return nil, fmt.Errorf("invalid annotation %s: %v", annotationNodeAffinity, err)
+	}
+	return convertSlice(rules, func(item nodeAffinitySpec) NodeAffinityInfo { return NodeAffinityInfo(item) }), nil
+}
+
+// toleratesTaints 是否容忍节点上所有影响调度的污点
+func toleratesTaints(tolerations []TolerationInfo, taints []TaintInfo) (bool, string) {
+	for _, taint := range taints {
+		if taint.Effect == string(corev1.TaintEffectPreferNoSchedule) {
+			continue
+		}
+		tolerated := false
+		for _, toleration := range tolerations {
+			if toleration.Effect != "" && toleration.Effect != taint.Effect {
+				continue
+			}
+			if toleration.Operator == string(corev1.TolerationOpExists) && (toleration.Key == "" || toleration.Key == taint.Key) {
+				tolerated = true
+			} else if toleration.Key == taint.Key && toleration.Value == taint.Value {
+				tolerated = true
+			}
+			if tolerated {
+				break
+			}
+		}
+		if !tolerated {
+			return false, fmt.Sprintf("taint %s=%s:%s not tolerated", taint.Key, taint.Value, taint.Effect)
+		}
+	}
+	return true, ""
+}
+
+// matchNodeAffinity 节点标签是否满足亲和性规则
+func matchNodeAffinity(rule NodeAffinityInfo, labels map[string]string) bool {
+	value, exists := labels[rule.Key]
+	switch corev1.NodeSelectorOperator(rule.Operator) {
+	case corev1.NodeSelectorOpIn:
+		return exists && containsString(rule.Values, value)
+	case corev1.NodeSelectorOpNotIn:
+		return !exists || !containsString(rule.Values, value)
+	case corev1.NodeSelectorOpExists:
+		return exists
+	case corev1.NodeSelectorOpDoesNotExist:
+		return !exists
+	case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+		if !exists || len(rule.Values) != 1 {
+			return false
+		}
+		nodeValue, err1 := strconv.ParseInt(value, 10, 64)
+		ruleValue, err2 := strconv.ParseInt(rule.Values[0], 10, 64)
+		if err1 != nil || err2 != nil {
+			return false
+		}
+		if rule.Operator == string(corev1.NodeSelectorOpGt) {
+			return nodeValue > ruleValue
+		}
+		return nodeValue < ruleValue
+	}
+	return false
+}
+
+func validateScheduling(info *CreateReqInfo) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, toleration := range info.Tolerations {
+		idxPath := field.NewPath("Tolerations").Index(i)
+		if toleration.Key != "" {
+			for _, msg := range validation.IsQualifiedName(toleration.Key) {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("Key"), toleration.Key, msg))
+			}
+		}
+		if toleration.Operator != "" && !containsString(tolerationOperators, toleration.Operator) {
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("Operator"), toleration.Operator, tolerationOperators))
+		}
+		if toleration.Operator == string(corev1.TolerationOpExists) && toleration.Value != "" {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("Value"), toleration.Value, "must be empty when Operator is Exists"))
+		}
+		if toleration.Key == "" && toleration.Operator != string(corev1.TolerationOpExists) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("Operator"), toleration.Operator, "must be Exists when Key is empty"))
+		}
+		if toleration.Effect != "" && !containsString(taintEffects, toleration.Effect) {
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("Effect"), toleration.Effect, taintEffects))
+		}
+		if toleration.Seconds != nil && toleration.Effect != string(corev1.TaintEffectNoExecute) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("Seconds"), *toleration.Seconds, "only valid when Effect is NoExecute"))
+		}
+	}
+	for i, rule := range info.NodeAffinity {
+		idxPath := field.NewPath("NodeAffinity").Index(i)
+		for _, msg := range validation.IsQualifiedName(rule.Key) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("Key"), rule.Key, msg))
+		}
+		switch corev1.NodeSelectorOperator(rule.Operator) {
+		case corev1.NodeSelectorOpIn, corev1.NodeSelectorOpNotIn:
+			if len(rule.Values) == 0 {
+				allErrs = append(allErrs, field.Required(idxPath.Child("Values"), "must be specified when Operator is In or NotIn"))
+			}
+		case corev1.NodeSelectorOpExists, corev1.NodeSelectorOpDoesNotExist:
+			if len(rule.Values) > 0 {
+				allErrs = append(allErrs, field.Forbidden(idxPath.Child("Values"), "may not be specified when Operator is Exists or DoesNotExist"))
+			}
+		case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+			if len(rule.Values) != 1 {
+				allErrs = append(allErrs, field.Required(idxPath.Child("Values"), "must be a single integer when Operator is Gt or Lt"))
+			} else if _, err := strconv.ParseInt(rule.Values[0], 10, 64); err != nil {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("Values"), rule.Values[0], "must be an integer"))
+			}
+		default:
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("Operator"), rule.Operator, affinityOperators))
+		}
+		if rule.Weight < 0 || rule.Weight > 100 {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("Weight"), rule.Weight, "must be in the range 0-100"))
+		}
+	}
+	if info.PriorityClassName != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(info.PriorityClassName) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("PriorityClassName"), info.PriorityClassName, msg))
+		}
+	}
+	if info.DNS != nil {
+		dnsPath := field.NewPath("DNS")
+		if info.DNS.Policy != "" && !containsString(dnsPolicies, info.DNS.Policy) {
+			allErrs = append(allErrs, field.NotSupported(dnsPath.Child("Policy"), info.DNS.Policy, dnsPolicies))
+		}
+		if info.DNS.Policy == string(corev1.DNSNone) && len(info.DNS.Nameservers) == 0 {
+			allErrs = append(allErrs, field.Required(dnsPath.Child("Nameservers"), "must be specified when Policy is None"))
+		}
+		for i, nameserver := range info.DNS.Nameservers {
+			if net.ParseIP(nameserver) == nil {
+				allErrs = append(allErrs, field.Invalid(dnsPath.Child("Nameservers").Index(i), nameserver, "must be a valid IP address"))
+			}
+		}
+		for i, search := range info.DNS.Searches {
+			for _, msg := range validation.IsDNS1123Subdomain(strings.TrimSuffix(search, ".")) {
+				allErrs = append(allErrs, field.Invalid(dnsPath.Child("Searches").Index(i), search, msg))
+			}
+		}
+	}
+	for i, alias := range info.HostAliases {
+		idxPath := field.NewPath("HostAliases").Index(i)
+		if net.ParseIP(alias.IP) == nil {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("IP"), alias.IP, "must be a valid IP address"))
+		}
+		if len(alias.Hostnames) == 0 {
+			allErrs = append(allErrs, field.Required(idxPath.Child("Hostnames"), ""))
+		}
+		for j, hostname := range alias.Hostnames {
+			for _, msg := range validation.IsDNS1123Subdomain(hostname) {
+				allErrs = append(allErrs, field.Invalid(idxPath.Child("Hostnames").Index(j), hostname, msg))
+			}
+		}
+	}
+	return allErrs
+}
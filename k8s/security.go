@@ -0,0 +1,144 @@
+package k8s
+
+import (
+	"fmt"
+	"github.com/golang/protobuf/proto"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"strings"
+)
+
+/**
+ *    Description: 细粒度的安全配置: Linux能力, 运行用户, 只读根文件系统, seccomp/AppArmor 以及设备挂载
+ *    Date: 2026/10/19
+ */
+
+const (
+	SeccompRuntimeDefault  = "RuntimeDefault"
+	SeccompUnconfined      = "Unconfined"
+	AppArmorRuntimeDefault = "runtime/default"
+	AppArmorUnconfined     = "unconfined"
+	profileLocalhostPrefix = "localhost/"
+	appArmorAnnotation     = "container.apparmor.security.beta.kubernetes.io/"
+)
+
+// normalizeCapability 能力名称统一为大写并去掉 CAP_ 前缀
+func normalizeCapability(name string) corev1.Capability {
+	return corev1.Capability(strings.TrimPrefix(strings.ToUpper(name), "CAP_"))
+}
+
+// seccompProfile 转换为k8s的 seccomp 配置
+func seccompProfile(profile string) *corev1.SeccompProfile {
+	switch {
+	case profile == "":
+		return nil
+	case profile == SeccompRuntimeDefault:
+		return &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+	case profile == SeccompUnconfined:
+		return &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeUnconfined}
+	default:
+		return &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeLocalhost, LocalhostProfile: proto.String(strings.TrimPrefix(profile, profileLocalhostPrefix))}
+	}
+}
+
+// applySecurity 将安全配置转换到创建信息中
+func applySecurity(createInfo *ContainerCreateInfo, security SecurityInfo) {
+	for _, name := range security.CapAdd {
+		createInfo.CapAdd = append(createInfo.CapAdd, normalizeCapability(name))
+	}
+	for _, name := range security.CapDrop {
+		createInfo.CapDrop = append(createInfo.CapDrop, normalizeCapability(name))
+	}
+	createInfo.RunAsUser = security.RunAsUser
+	createInfo.RunAsGroup = security.RunAsGroup
+	createInfo.FsGroup = security.FsGroup
+	createInfo.ReadOnlyRoot = security.ReadOnlyRootFs
+	createInfo.Seccomp = seccompProfile(security.SeccompProfile)
+	// k8s v1.26 AppArmor 仅支持通过注解配置
+	if security.AppArmorProfile != "" {
+		if createInfo.Annotations == nil {
+			createInfo.Annotations = make(map[string]string)
+		}
+		createInfo.Annotations[appArmorAnnotation+createInfo.Name] = security.AppArmorProfile
+	}
+	for i, device := range security.Device {
+		name := fmt.Sprintf("%s-dev-%d", createInfo.Name, i)
+		createInfo.Volumes = append(createInfo.Volumes, corev1.Volume{Name: name, VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: device.HostPath, Type: (*corev1.HostPathType)(proto.String(string(corev1.HostPathCharDev)))}}})
+		createInfo.VolumeMounts = append(createInfo.VolumeMounts, corev1.VolumeMount{Name: name, MountPath: device.ContainerPath})
+	}
+}
+
+// extraSecurityContext 生成init容器以及边车容器的安全配置, 禁止特权模式以及提权
+func extraSecurityContext(info *ContainerCreateInfo) *corev1.SecurityContext {
+	securityContext := &corev1.SecurityContext{
+		Privileged:               proto.Bool(false),
+		AllowPrivilegeEscalation: proto.Bool(false),
+		RunAsUser:                info.RunAsUser,
+		RunAsGroup:               info.RunAsGroup,
+		SeccompProfile:           info.Seccomp,
+	}
+	if info.ReadOnlyRoot {
+		securityContext.ReadOnlyRootFilesystem = proto.Bool(true)
+	}
+	if len(info.CapDrop) > 0 {
+		securityContext.Capabilities = &corev1.Capabilities{Drop: info.CapDrop}
+	}
+	return securityContext
+}
+
+// containerSecurityContext 生成容器的安全配置
+func containerSecurityContext(info *ContainerCreateInfo) *corev1.SecurityContext {
+	securityContext := &corev1.SecurityContext{
+		Privileged:     proto.Bool(info.Privileged), // 是否使用特权模式, 有的APP需要使用
+		RunAsUser:      info.RunAsUser,
+		RunAsGroup:     info.RunAsGroup,
+		SeccompProfile: info.Seccomp,
+	}
+	if info.ReadOnlyRoot {
+		securityContext.ReadOnlyRootFilesystem = proto.Bool(true)
+	}
+	if len(info.CapAdd) > 0 || len(info.CapDrop) > 0 {
+		securityContext.Capabilities = &corev1.Capabilities{Add: info.CapAdd, Drop: info.CapDrop}
+	}
+	return securityContext
+}
+
+func validateSecurity(security SecurityInfo, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, name := range security.CapAdd {
+		allErrs = append(allErrs, validateCapability(name, fldPath.Child("CapAdd").Index(i))...)
+	}
+	for i, name := range security.CapDrop {
+		allErrs = append(allErrs, validateCapability(name, fldPath.Child("CapDrop").Index(i))...)
+	}
+	names := []string{"RunAsUser", "RunAsGroup", "FsGroup"}
+	for i, id := range []*int64{security.RunAsUser, security.RunAsGroup, security.FsGroup} {
+		if id != nil && *id < 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child(names[i]), *id, "must be greater than or equal to 0"))
+		}
+	}
+	if p := security.SeccompProfile; p != "" && p != SeccompRuntimeDefault && p != SeccompUnconfined && !isLocalhostProfile(p) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("SeccompProfile"), p, []string{SeccompRuntimeDefault, SeccompUnconfined, profileLocalhostPrefix + "<profile>"}))
+	}
+	if p := security.AppArmorProfile; p != "" && p != AppArmorRuntimeDefault && p != AppArmorUnconfined && !isLocalhostProfile(p) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("AppArmorProfile"), p, []string{AppArmorRuntimeDefault, AppArmorUnconfined, profileLocalhostPrefix + "<profile>"}))
+	}
+	for i, device := range security.Device {
+		idxPath := fldPath.Child("Device").Index(i)
+		allErrs = append(allErrs, validateAbsPath(device.HostPath, idxPath.Child("HostPath"))...)
+		allErrs = append(allErrs, validateAbsPath(device.ContainerPath, idxPath.Child("ContainerPath"))...)
+	}
+	return allErrs
+}
+
+func validateCapability(name string, fldPath *field.Path) field.ErrorList {
+	capability := string(normalizeCapability(name))
+	if capability == "" || strings.Trim(capability, "ABCDEFGHIJKLMNOPQRSTUVWXYZ_") != "" {
+		return field.ErrorList{field.Invalid(fldPath, name, "must be a linux capability name, e.g. NET_ADMIN")}
+	}
+	return nil
+}
+
+func isLocalhostProfile(profile string) bool {
+	return strings.HasPrefix(profile, profileLocalhostPrefix) && len(profile) > len(profileLocalhostPrefix)
+}
@@ -0,0 +1,379 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	logger "github.com/alecthomas/log4go"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+	"sort"
+	"strings"
+)
+
+/**
+ *    Description: 声明式的app描述文件(YAML/JSON), 支持从文件创建或者更新app, 以及从已有的StatefulSet导出
+ *    Date: 2026/10/19
+ */
+
+const (
+	AppSpecAPIVersion = "k8s-core-components/v1"
+	AppSpecKind       = "App"
+	AppSpecCreated    = "created"
+	AppSpecUpdated    = "updated"
+)
+
+type (
+	// AppSpec app描述文件, 格式:
+	//
+	//	apiVersion: k8s-core-components/v1
+	//	kind: App
+	//	metadata:
+	//	  name: nginx
+	//	spec:
+	//	  nodeName: 127.0.0.1
+	//	  image: nginx:1.25
+	//	  port:
+	//	  - {protocol: TCP, innerPort: 80, outerPort: 8080}
+	//
+	// spec 的字段与 CreateReqInfo 一致, 字段名称使用小驼峰, metadata.name 为空时使用 spec.name;
+	// 描述文件的编码格式见 specInfo.go, 需要通过 ParseAppSpecs 以及 MarshalAppSpecs 读写
+	AppSpec struct {
+		APIVersion string
+		Kind       string
+		Metadata   AppSpecMetadata
+		Spec       CreateReqInfo
+	}
+	AppSpecMetadata struct {
+		Name string
+	}
+	// AppSpecResult 单个app的应用结果
+	AppSpecResult struct {
+		Name   string
+		Action string // created, updated
+		Error  error
+	}
+)
+
+// ParseAppSpecs 解析app描述文件, 支持YAML(多个文档使用 --- 分隔)以及JSON, 不允许未知的字段
+func ParseAppSpecs(data []byte) ([]*AppSpec, error) {
+	var specs []*AppSpec
+	for i, doc := range splitYAMLDocuments(data) {
+		document := &appSpecDocument{}
+		if err := yaml.UnmarshalStrict(doc, document); err != nil {
+			return nil, fmt.Errorf("document %d: %v", i, err)
+		}
+		spec := document.appSpec()
+		if spec.APIVersion != AppSpecAPIVersion || spec.Kind != AppSpecKind {
+			return nil, fmt.Errorf("document %d: unsupported apiVersion %q kind %q, expected %s %s", i, spec.APIVersion, spec.Kind, AppSpecAPIVersion, AppSpecKind)
+		}
+		switch {
+		case spec.Spec.Name == "":
+			spec.Spec.Name = spec.Metadata.Name
+		case spec.Metadata.Name == "":
+			spec.Metadata.Name = spec.Spec.Name
+		case spec.Metadata.Name != spec.Spec.Name:
+			return nil, fmt.Errorf("document %d: metadata.name %q does not match spec.name %q", i, spec.Metadata.Name, spec.Spec.Name)
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// MarshalAppSpecs 将app描述输出为YAML, 多个app使用 --- 分隔
+func MarshalAppSpecs(specs ...*AppSpec) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, spec := range specs {
+		data, err := yaml.Marshal(toAppSpecDocument(spec))
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(data)
+	}
+	return buf.Bytes(), nil
+}
+
+// NewAppSpec 使用创建信息生成app描述
+func NewAppSpec(info *CreateReqInfo) *AppSpec {
+	return &AppSpec{APIVersion: AppSpecAPIVersion, Kind: AppSpecKind, Metadata: AppSpecMetadata{Name: info.Name}, Spec: *info}
+}
+
+// splitYAMLDocuments 按照 --- 分隔多个文档, 忽略空文档
+func splitYAMLDocuments(data []byte) [][]byte {
+	var docs [][]byte
+	var current bytes.Buffer
+	flush := func() {
+		if len(bytes.TrimSpace(current.Bytes())) > 0 {
+			docs = append(docs, append([]byte(nil), current.Bytes()...))
+		}
+		current.Reset()
+	}
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if trimmed := bytes.TrimRight(line, " \t\r\n"); bytes.Equal(trimmed, []byte("---")) {
+			flush()
+			continue
+		}
+		current.Write(line)
+	}
+	flush()
+	return docs
+}
+
+func (api *k8sApi) statefulSetGet(name, namespace string) (*v1.StatefulSet, error) {
+	return api.client.AppsV1().StatefulSets(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+}
+
+// AppSpecApply 按照描述文件创建或者更新app, app已存在时更新, 否则创建; 单个app失败不影响其他app
+func (manage *ManagerK8s) AppSpecApply(data []byte, isTry bool) ([]AppSpecResult, error) {
+	specs, err := ParseAppSpecs(data)
+	if err != nil {
+		return nil, logger.Warn("app spec 解析失败: %v", err)
+	}
+	results := make([]AppSpecResult, 0, len(specs))
+	for _, spec := range specs {
+		info := spec.Spec
+		result := AppSpecResult{Name: info.Name, Action: AppSpecUpdated}
+		if _, err = manage.api.statefulSetGet(info.Name, manage.appNamespace); apierrors.IsNotFound(err) {
+			result.Action = AppSpecCreated
+			result.Error = manage.StatefulSetCreate(&info, isTry)
+		} else if err != nil {
+			result.Error = err
+		} else {
+			result.Error = manage.StatefulSetUpdate(&info, isTry)
+		}
+		if result.Error != nil {
+			logger.Warn("【容器: %s】app spec %s 失败: %v", info.Name, result.Action, result.Error)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// AppSpecExport 根据已有的StatefulSet还原app描述
+func (manage *ManagerK8s) AppSpecExport(name string) (*AppSpec, error) {
+	logger.Info("【容器: %s】export app spec 命令执行中...", name)
+	statefulSet, err := manage.api.statefulSetGet(name, manage.appNamespace)
+	if err != nil {
+		return nil, err
+	}
+	return NewAppSpec(statefulSetToReqInfo(statefulSet, manage.options.MacAddressAnnotation)), nil
+}
+
+// statefulSetToReqInfo 将StatefulSet还原为创建信息, 是 buildCreateInfo 以及 newStatefulSet 的逆过程
+func statefulSetToReqInfo(statefulSet *v1.StatefulSet, macAnnotation string) *CreateReqInfo {
+	podSpec := statefulSet.Spec.Template.Spec
+	info := &CreateReqInfo{
+		Name:                   statefulSet.Name,
+		NodeName:               podSpec.NodeSelector[labelHostname],
+		HostNetwork:            podSpec.HostNetwork,
+		Restart:                string(podSpec.RestartPolicy),
+		TerminationGracePeriod: podSpec.TerminationGracePeriodSeconds,
+		PriorityClassName:      podSpec.PriorityClassName,
+	}
+	if info.NodeName == "" {
+		info.NodeName = statefulSet.Labels[labelNodeIP]
+	}
+	for _, key := range sortedKeys(statefulSet.Labels) {
+		if key != labelNodeIP && key != labelApp {
+			info.Label = append(info.Label, LabelInfo{Key: key, Value: statefulSet.Labels[key]})
+		}
+	}
+	volumes := make(map[string]corev1.Volume, len(podSpec.Volumes))
+	for _, volume := range podSpec.Volumes {
+		volumes[volume.Name] = volume
+	}
+	if len(podSpec.Containers) == 0 {
+		return info
+	}
+	main := podSpec.Containers[0]
+	spec := containerToSpecInfo(main, volumes)
+	info.Image, info.Env, info.Port, info.Volume, info.Resource = spec.Image, spec.Env, spec.Port, spec.Volume, spec.Resource
+	info.Command, info.Args, info.WorkingDir = main.Command, main.Args, main.WorkingDir
+	info.ImagePullPolicy = string(main.ImagePullPolicy)
+	// 设备以字符设备的方式挂载
+	for _, mount := range main.VolumeMounts {
+		if volume, ok := volumes[mount.Name]; ok && isHostPathType(volume, corev1.HostPathCharDev) {
+			info.Security.Device = append(info.Security.Device, DeviceInfo{HostPath: volume.HostPath.Path, ContainerPath: mount.MountPath})
+		}
+	}
+	// 安全配置, ULIMIT 添加的 SYS_RESOURCE 能力在还原 ULIMIT 时去掉
+	var ulimit string
+	if main.Lifecycle != nil {
+		ulimit, info.PostStart = splitPostStart(main.Lifecycle.PostStart)
+		info.PreStop = hookInfo(main.Lifecycle.PreStop)
+	}
+	if ctx := main.SecurityContext; ctx != nil {
+		if ctx.Privileged != nil && *ctx.Privileged {
+			info.Env = append(info.Env, EnvInfo{Key: ENV_PRIVILEGED, Value: "true"})
+		}
+		if ctx.Capabilities != nil {
+			removed := ulimit == ""
+			for _, capability := range ctx.Capabilities.Add {
+				if !removed && capability == "SYS_RESOURCE" {
+					removed = true
+					continue
+				}
+				info.Security.CapAdd = append(info.Security.CapAdd, string(capability))
+			}
+			for _, capability := range ctx.Capabilities.Drop {
+				info.Security.CapDrop = append(info.Security.CapDrop, string(capability))
+			}
+		}
+		info.Security.RunAsUser, info.Security.RunAsGroup = ctx.RunAsUser, ctx.RunAsGroup
+		info.Security.ReadOnlyRootFs = ctx.ReadOnlyRootFilesystem != nil && *ctx.ReadOnlyRootFilesystem
+		if profile := ctx.SeccompProfile; profile != nil {
+			if profile.Type == corev1.SeccompProfileTypeLocalhost && profile.LocalhostProfile != nil {
+				info.Security.SeccompProfile = profileLocalhostPrefix + *profile.LocalhostProfile
+			} else {
+				info.Security.SeccompProfile = string(profile.Type)
+			}
+		}
+	}
+	if ulimit != "" {
+		info.Env = append(info.Env, EnvInfo{Key: ENV_ULIMIT_NAME, Value: ulimit})
+	}
+	annotations := statefulSet.Spec.Template.Annotations
+	if mac := annotations[macAnnotation]; macAnnotation != "" && mac != "" {
+		info.Env = append(info.Env, EnvInfo{Key: ENV_MACADDRESS, Value: mac})
+	}
+	info.Security.AppArmorProfile = annotations[appArmorAnnotation+main.Name]
+	if podSpec.SecurityContext != nil {
+		info.Security.FsGroup = podSpec.SecurityContext.FSGroup
+	}
+	for _, container := range podSpec.InitContainers {
+		info.InitContainers = append(info.InitContainers, containerToSpecInfo(container, volumes))
+	}
+	for _, container := range podSpec.Containers[1:] {
+		info.Sidecars = append(info.Sidecars, containerToSpecInfo(container, volumes))
+	}
+	// 调度控制
+	for _, toleration := range podSpec.Tolerations {
+		info.Tolerations = append(info.Tolerations, TolerationInfo{Key: toleration.Key, Operator: string(toleration.Operator), Value: toleration.Value, Effect: string(toleration.Effect), Seconds: toleration.TolerationSeconds})
+	}
+	// 节点亲和性保存在StatefulSet的注解中, 之前创建的app从pod的配置中还原
+	if rules, err := savedNodeAffinity(statefulSet.Annotations); err != nil {
+		logger.Warn("【容器: %s】还原节点亲和性失败: %v", statefulSet.Name, err)
+	} else {
+		info.NodeAffinity = rules
+	}
+	if affinity := podSpec.Affinity; affinity != nil {
+		if nodeAffinity := affinity.NodeAffinity; nodeAffinity != nil {
+			if required := nodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution; required != nil && len(required.NodeSelectorTerms) > 0 {
+				for _, requirement := range required.NodeSelectorTerms[0].MatchExpressions {
+					info.NodeAffinity = append(info.NodeAffinity, NodeAffinityInfo{Key: requirement.Key, Operator: string(requirement.Operator), Values: requirement.Values})
+				}
+			}
+			for _, preferred := range nodeAffinity.PreferredDuringSchedulingIgnoredDuringExecution {
+				for _, requirement := range preferred.Preference.MatchExpressions {
+					info.NodeAffinity = append(info.NodeAffinity, NodeAffinityInfo{Key: requirement.Key, Operator: string(requirement.Operator), Values: requirement.Values, Weight: preferred.Weight})
+				}
+			}
+		}
+	}
+	// k8s默认的DNS策略为 ClusterFirst, 与默认值相同时不导出
+	if podSpec.DNSConfig != nil || (podSpec.DNSPolicy != "" && podSpec.DNSPolicy != corev1.DNSClusterFirst) {
+		info.DNS = &DNSInfo{Policy: string(podSpec.DNSPolicy)}
+		if config := podSpec.DNSConfig; config != nil {
+			info.DNS.Nameservers, info.DNS.Searches = config.Nameservers, config.Searches
+			for _, option := range config.Options {
+				if option.Value != nil {
+					info.DNS.Options = append(info.DNS.Options, option.Name+":"+*option.Value)
+				} else {
+					info.DNS.Options = append(info.DNS.Options, option.Name)
+				}
+			}
+		}
+	}
+	for _, alias := range podSpec.HostAliases {
+		info.HostAliases = append(info.HostAliases, HostAliasInfo{IP: alias.IP, Hostnames: alias.Hostnames})
+	}
+	for _, secret := range podSpec.ImagePullSecrets {
+		info.ImagePullSecrets = append(info.ImagePullSecrets, secret.Name)
+	}
+	return info
+}
+
+// containerToSpecInfo 还原容器的镜像, 命令, 环境变量, 端口, 目录卷以及资源配置
+func containerToSpecInfo(container corev1.Container, volumes map[string]corev1.Volume) ContainerSpecInfo {
+	spec := ContainerSpecInfo{Name: container.Name, Image: container.Image, Command: container.Command, Args: container.Args, WorkingDir: container.WorkingDir}
+	for _, env := range container.Env {
+		if env.ValueFrom == nil {
+			spec.Env = append(spec.Env, EnvInfo{Key: env.Name, Value: env.Value})
+		}
+	}
+	for _, port := range container.Ports {
+		spec.Port = append(spec.Port, PortInfo{Protocol: string(port.Protocol), InnerPort: uint16(port.ContainerPort), OuterPort: uint16(port.HostPort)})
+	}
+	for _, mount := range container.VolumeMounts {
+		if volume, ok := volumes[mount.Name]; ok && volume.HostPath != nil && !isHostPathType(volume, corev1.HostPathCharDev) {
+			spec.Volume = append(spec.Volume, VolumeInfo{InnerPath: mount.MountPath, OuterPath: volume.HostPath.Path})
+		}
+	}
+	quantity := func(list corev1.ResourceList, name corev1.ResourceName) string {
+		if value, ok := list[name]; ok {
+			return value.String()
+		}
+		return ""
+	}
+	spec.Resource = ResourceReqInfo{
+		CpuRequest: quantity(container.Resources.Requests, corev1.ResourceCPU),
+		CpuLimit:   quantity(container.Resources.Limits, corev1.ResourceCPU),
+		MemRequest: quantity(container.Resources.Requests, corev1.ResourceMemory),
+		MemLimit:   quantity(container.Resources.Limits, corev1.ResourceMemory),
+	}
+	return spec
+}
+
+func isHostPathType(volume corev1.Volume, pathType corev1.HostPathType) bool {
+	return volume.HostPath != nil && volume.HostPath.Type != nil && *volume.HostPath.Type == pathType
+}
+
+// hookInfo 还原生命周期钩子
+func hookInfo(handler *corev1.LifecycleHandler) *HookInfo {
+	switch {
+	case handler == nil:
+		return nil
+	case handler.Exec != nil:
+		return &HookInfo{Command: handler.Exec.Command}
+	case handler.HTTPGet != nil:
+		return &HookInfo{HttpPath: handler.HTTPGet.Path, HttpPort: uint16(handler.HTTPGet.Port.IntValue())}
+	}
+	return nil
+}
+
+// splitPostStart 区分 ULIMIT 生成的 postStart 钩子以及请求的钩子, 返回 ULIMIT 的值以及请求的钩子
+func splitPostStart(handler *corev1.LifecycleHandler) (string, *HookInfo) {
+	if handler == nil || handler.Exec == nil {
+		return "", hookInfo(handler)
+	}
+	// ULIMIT 的钩子直接执行 prlimit, 与请求的钩子不会同时存在
+	command := handler.Exec.Command
+	if len(command) > 3 && command[0] == "prlimit" && command[1] == "--pid" && command[2] == "1" {
+		return ulimitValue(command[3:]), nil
+	}
+	return "", hookInfo(handler)
+}
+
+// ulimitValue prlimit 参数(--nofile=soft:hard)还原为 ULIMIT 的值
+func ulimitValue(args []string) string {
+	ulimits := make([]string, 0, len(args))
+	for _, arg := range args {
+		ulimits = append(ulimits, strings.TrimPrefix(arg, "--"))
+	}
+	return strings.Join(ulimits, ",")
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
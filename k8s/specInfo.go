@@ -0,0 +1,249 @@
+package k8s
+
+import "reflect"
+
+/**
+ *    Description: app描述文件的编码格式(字段名称使用小驼峰), 与 CreateReqInfo 分开定义, CreateReqInfo 自身的JSON编码保持不变
+ *    Date: 2026/10/19
+ */
+
+type (
+	appSpecDocument struct {
+		APIVersion string          `json:"apiVersion"`
+		Kind       string          `json:"kind"`
+		Metadata   appSpecMetadata `json:"metadata"`
+		Spec       appSpecInfo     `json:"spec"`
+	}
+	appSpecMetadata struct {
+		Name string `json:"name"`
+	}
+	// appSpecInfo 对应 CreateReqInfo, security 以及 resource 为空时不输出
+	appSpecInfo struct {
+		Name                   string             `json:"name,omitempty"`
+		NodeName               string             `json:"nodeName,omitempty"`
+		Image                  string             `json:"image,omitempty"`
+		HostNetwork            bool               `json:"hostNetwork,omitempty"`
+		Label                  []labelSpec        `json:"label,omitempty"`
+		Env                    []envSpec          `json:"env,omitempty"`
+		Port                   []portSpec         `json:"port,omitempty"`
+		Volume                 []volumeSpec       `json:"volume,omitempty"`
+		Restart                string             `json:"restart,omitempty"`
+		NodeLabel              []labelSpec        `json:"nodeLabel,omitempty"`
+		Security               *securitySpec      `json:"security,omitempty"`
+		Resource               *resourceSpec      `json:"resource,omitempty"`
+		Command                []string           `json:"command,omitempty"`
+		Args                   []string           `json:"args,omitempty"`
+		WorkingDir             string             `json:"workingDir,omitempty"`
+		PostStart              *hookSpec          `json:"postStart,omitempty"`
+		PreStop                *hookSpec          `json:"preStop,omitempty"`
+		TerminationGracePeriod *int64             `json:"terminationGracePeriod,omitempty"`
+		InitContainers         []containerSpec    `json:"initContainers,omitempty"`
+		Sidecars               []containerSpec    `json:"sidecars,omitempty"`
+		Tolerations            []tolerationSpec   `json:"tolerations,omitempty"`
+		NodeAffinity           []nodeAffinitySpec `json:"nodeAffinity,omitempty"`
+		PriorityClassName      string             `json:"priorityClassName,omitempty"`
+		DNS                    *dnsSpec           `json:"dns,omitempty"`
+		HostAliases            []hostAliasSpec    `json:"hostAliases,omitempty"`
+		ImagePullSecrets       []string           `json:"imagePullSecrets,omitempty"`
+		ImagePullPolicy        string             `json:"imagePullPolicy,omitempty"`
+	}
+	containerSpec struct {
+		Name       string        `json:"name,omitempty"`
+		Image      string        `json:"image,omitempty"`
+		Command    []string      `json:"command,omitempty"`
+		Args       []string      `json:"args,omitempty"`
+		WorkingDir string        `json:"workingDir,omitempty"`
+		Env        []envSpec     `json:"env,omitempty"`
+		Port       []portSpec    `json:"port,omitempty"`
+		Volume     []volumeSpec  `json:"volume,omitempty"`
+		Resource   *resourceSpec `json:"resource,omitempty"`
+	}
+	// 以下类型与对应的 *Info 类型字段一致, 可以直接转换
+	tolerationSpec struct {
+		Key      string `json:"key,omitempty"`
+		Operator string `json:"operator,omitempty"`
+		Value    string `json:"value,omitempty"`
+		Effect   string `json:"effect,omitempty"`
+		Seconds  *int64 `json:"seconds,omitempty"`
+	}
+	nodeAffinitySpec struct {
+		Key      string   `json:"key,omitempty"`
+		Operator string   `json:"operator,omitempty"`
+		Values   []string `json:"values,omitempty"`
+		Weight   int32    `json:"weight,omitempty"`
+	}
+	dnsSpec struct {
+		Policy      string   `json:"policy,omitempty"`
+		Nameservers []string `json:"nameservers,omitempty"`
+		Searches    []string `json:"searches,omitempty"`
+		Options     []string `json:"options,omitempty"`
+	}
+	hostAliasSpec struct {
+		IP        string   `json:"ip,omitempty"`
+		Hostnames []string `json:"hostnames,omitempty"`
+	}
+	hookSpec struct {
+		Command  []string `json:"command,omitempty"`
+		HttpPath string   `json:"httpPath,omitempty"`
+		HttpPort uint16   `json:"httpPort,omitempty"`
+	}
+	resourceSpec struct {
+		CpuRequest string `json:"cpuRequest,omitempty"`
+		CpuLimit   string `json:"cpuLimit,omitempty"`
+		MemRequest string `json:"memRequest,omitempty"`
+		MemLimit   string `json:"memLimit,omitempty"`
+	}
+	securitySpec struct {
+		CapAdd          []string     `json:"capAdd,omitempty"`
+		CapDrop         []string     `json:"capDrop,omitempty"`
+		RunAsUser       *int64       `json:"runAsUser,omitempty"`
+		RunAsGroup      *int64       `json:"runAsGroup,omitempty"`
+		FsGroup         *int64       `json:"fsGroup,omitempty"`
+		ReadOnlyRootFs  bool         `json:"readOnlyRootFs,omitempty"`
+		SeccompProfile  string       `json:"seccompProfile,omitempty"`
+		AppArmorProfile string       `json:"appArmorProfile,omitempty"`
+		Device          []deviceSpec `json:"device,omitempty"`
+	}
+	deviceSpec struct {
+		HostPath      string `json:"hostPath,omitempty"`
+		ContainerPath string `json:"containerPath,omitempty"`
+	}
+	labelSpec struct {
+		Key   string `json:"key,omitempty"`
+		Value string `json:"value,omitempty"`
+	}
+	envSpec struct {
+		Key   string `json:"key,omitempty"`
+		Value string `json:"value,omitempty"`
+	}
+	portSpec struct {
+		Protocol  string `json:"protocol,omitempty"`
+		InnerPort uint16 `json:"innerPort,omitempty"`
+		OuterPort uint16 `json:"outerPort,omitempty"`
+	}
+	volumeSpec struct {
+		InnerPath string `json:"innerPath,omitempty"`
+		OuterPath string `json:"outerPath,omitempty"`
+	}
+)
+
+// convertSlice 逐个转换切片元素, 空切片返回nil
+func convertSlice[From, To any](items []From, convert func(From) To) []To {
+	if len(items) == 0 {
+		return nil
+	}
+	result := make([]To, 0, len(items))
+	for _, item := range items {
+		result = append(result, convert(item))
+	}
+	return result
+}
+
+// toAppSpecDocument app描述转换为编码格式
+func toAppSpecDocument(spec *AppSpec) appSpecDocument {
+	info := &spec.Spec
+	doc := appSpecDocument{APIVersion: spec.APIVersion, Kind: spec.Kind, Metadata: appSpecMetadata(spec.Metadata)}
+	doc.Spec = appSpecInfo{
+		Name: info.Name, NodeName: info.NodeName, Image: info.Image, HostNetwork: info.HostNetwork,
+		Label:     convertSlice(info.Label, func(item LabelInfo) labelSpec { return labelSpec(item) }),
+		Env:       convertSlice(info.Env, func(item EnvInfo) envSpec { return envSpec(item) }),
+		Port:      convertSlice(info.Port, func(item PortInfo) portSpec { return portSpec(item) }),
+		Volume:    convertSlice(info.Volume, func(item VolumeInfo) volumeSpec { return volumeSpec(item) }),
+		Restart:   info.Restart,
+		NodeLabel: convertSlice(info.NodeLabel, func(item LabelInfo) labelSpec { return labelSpec(item) }),
+		Resource:  toResourceSpec(info.Resource),
+		Command:   info.Command, Args: info.Args, WorkingDir: info.WorkingDir,
+		PostStart: (*hookSpec)(info.PostStart), PreStop: (*hookSpec)(info.PreStop),
+		TerminationGracePeriod: info.TerminationGracePeriod,
+		InitContainers:         convertSlice(info.InitContainers, toContainerSpec),
+		Sidecars:               convertSlice(info.Sidecars, toContainerSpec),
+		Tolerations:            convertSlice(info.Tolerations, func(item TolerationInfo) tolerationSpec { return tolerationSpec(item) }),
+		NodeAffinity:           convertSlice(info.NodeAffinity, func(item NodeAffinityInfo) nodeAffinitySpec { return nodeAffinitySpec(item) }),
+		PriorityClassName:      info.PriorityClassName,
+		DNS:                    (*dnsSpec)(info.DNS),
+		HostAliases:            convertSlice(info.HostAliases, func(item HostAliasInfo) hostAliasSpec { return hostAliasSpec(item) }),
+		ImagePullSecrets:       info.ImagePullSecrets,
+		ImagePullPolicy:        info.ImagePullPolicy,
+	}
+	if !reflect.ValueOf(info.Security).IsZero() {
+		doc.Spec.Security = &securitySpec{
+			CapAdd: info.Security.CapAdd, CapDrop: info.Security.CapDrop,
+			RunAsUser: info.Security.RunAsUser, RunAsGroup: info.Security.RunAsGroup, FsGroup: info.Security.FsGroup,
+			ReadOnlyRootFs: info.Security.ReadOnlyRootFs, SeccompProfile: info.Security.SeccompProfile, AppArmorProfile: info.Security.AppArmorProfile,
+			Device: convertSlice(info.Security.Device, func(item DeviceInfo) deviceSpec { return deviceSpec(item) }),
+		}
+	}
+	return doc
+}
+
+// appSpec 编码格式转换为app描述
+func (doc *appSpecDocument) appSpec() *AppSpec {
+	spec := doc.Spec
+	info := CreateReqInfo{
+		Name: spec.Name, NodeName: spec.NodeName, Image: spec.Image, HostNetwork: spec.HostNetwork,
+		Label:     convertSlice(spec.Label, func(item labelSpec) LabelInfo { return LabelInfo(item) }),
+		Env:       convertSlice(spec.Env, func(item envSpec) EnvInfo { return EnvInfo(item) }),
+		Port:      convertSlice(spec.Port, func(item portSpec) PortInfo { return PortInfo(item) }),
+		Volume:    convertSlice(spec.Volume, func(item volumeSpec) VolumeInfo { return VolumeInfo(item) }),
+		Restart:   spec.Restart,
+		NodeLabel: convertSlice(spec.NodeLabel, func(item labelSpec) LabelInfo { return LabelInfo(item) }),
+		Resource:  fromResourceSpec(spec.Resource),
+		Command:   spec.Command, Args: spec.Args, WorkingDir: spec.WorkingDir,
+		PostStart: (*HookInfo)(spec.PostStart), PreStop: (*HookInfo)(spec.PreStop),
+		TerminationGracePeriod: spec.TerminationGracePeriod,
+		InitContainers:         convertSlice(spec.InitContainers, fromContainerSpec),
+		Sidecars:               convertSlice(spec.Sidecars, fromContainerSpec),
+		Tolerations:            convertSlice(spec.Tolerations, func(item tolerationSpec) TolerationInfo { return TolerationInfo(item) }),
+		NodeAffinity:           convertSlice(spec.NodeAffinity, func(item nodeAffinitySpec) NodeAffinityInfo { return NodeAffinityInfo(item) }),
+		PriorityClassName:      spec.PriorityClassName,
+		DNS:                    (*DNSInfo)(spec.DNS),
+		HostAliases:            convertSlice(spec.HostAliases, func(item hostAliasSpec) HostAliasInfo { return HostAliasInfo(item) }),
+		ImagePullSecrets:       spec.ImagePullSecrets,
+		ImagePullPolicy:        spec.ImagePullPolicy,
+	}
+	if security := spec.Security; security != nil {
+		info.Security = SecurityInfo{
+			CapAdd: security.CapAdd, CapDrop: security.CapDrop,
+			RunAsUser: security.RunAsUser, RunAsGroup: security.RunAsGroup, FsGroup: security.FsGroup,
+			ReadOnlyRootFs: security.ReadOnlyRootFs, SeccompProfile: security.SeccompProfile, AppArmorProfile: security.AppArmorProfile,
+			Device: convertSlice(security.Device, func(item deviceSpec) DeviceInfo { return DeviceInfo(item) }),
+		}
+	}
+	return &AppSpec{APIVersion: doc.APIVersion, Kind: doc.Kind, Metadata: AppSpecMetadata(doc.Metadata), Spec: info}
+}
+
+func toContainerSpec(info ContainerSpecInfo) containerSpec {
+	return containerSpec{
+		Name: info.Name, Image: info.Image, Command: info.Command, Args: info.Args, WorkingDir: info.WorkingDir,
+		Env:      convertSlice(info.Env, func(item EnvInfo) envSpec { return envSpec(item) }),
+		Port:     convertSlice(info.Port, func(item PortInfo) portSpec { return portSpec(item) }),
+		Volume:   convertSlice(info.Volume, func(item VolumeInfo) volumeSpec { return volumeSpec(item) }),
+		Resource: toResourceSpec(info.Resource),
+	}
+}
+
+func fromContainerSpec(spec containerSpec) ContainerSpecInfo {
+	return ContainerSpecInfo{
+		Name: spec.Name, Image: spec.Image, Command: spec.Command, Args: spec.Args, WorkingDir: spec.WorkingDir,
+		Env:      convertSlice(spec.Env, func(item envSpec) EnvInfo { return EnvInfo(item) }),
+		Port:     convertSlice(spec.Port, func(item portSpec) PortInfo { return PortInfo(item) }),
+		Volume:   convertSlice(spec.Volume, func(item volumeSpec) VolumeInfo { return VolumeInfo(item) }),
+		Resource: fromResourceSpec(spec.Resource),
+	}
+}
+
+// toResourceSpec 未设置资源时返回nil, 输出时省略
+func toResourceSpec(info ResourceReqInfo) *resourceSpec {
+	if info == (ResourceReqInfo{}) {
+		return nil
+	}
+	spec := resourceSpec(info)
+	return &spec
+}
+
+func fromResourceSpec(spec *resourceSpec) ResourceReqInfo {
+	if spec == nil {
+		return ResourceReqInfo{}
+	}
+	return ResourceReqInfo(*spec)
+}
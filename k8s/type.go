@@ -13,6 +13,9 @@ const (
 	ENV_MACADDRESS    = "MACADDRESS"
 	RunningStatus     = "Running"
 	Action            = "start"
+	NodeHealthy       = "Healthy"  // 节点Ready
+	NodeNotReady      = "NotReady" // 节点未Ready
+	NodeUnknown       = "Unknown"  // 节点状态未知(失联)
 )
 
 // ContainerCreateInfo app 创建的容器信息
@@ -22,14 +25,40 @@ type (
 		NodeName string
 		Image    string
 		// 是否是"host模式服务"
-		HostNetwork  bool
-		Label        map[string]string
-		Env          []corev1.EnvVar
-		Port         []corev1.ContainerPort
-		Volumes      []corev1.Volume      // 挂载卷,映射主机路径
-		VolumeMounts []corev1.VolumeMount // 挂载卷,映射容器路径
-		Restart      string
-		Privileged   bool
+		HostNetwork       bool
+		Label             map[string]string
+		Env               []corev1.EnvVar
+		Port              []corev1.ContainerPort
+		Volumes           []corev1.Volume      // 挂载卷,映射主机路径
+		VolumeMounts      []corev1.VolumeMount // 挂载卷,映射容器路径
+		Restart           string
+		Privileged        bool
+		Annotations       map[string]string   // pod注解, 例如CNI插件设置MAC地址
+		AppAnnotations    map[string]string   // StatefulSet注解, 保存不写入pod配置的信息, 例如节点亲和性
+		CapAdd            []corev1.Capability // 添加的Linux能力
+		CapDrop           []corev1.Capability // 删除的Linux能力
+		UlimitHook        []string            // ULIMIT 生成的 postStart 命令
+		Command           []string
+		Args              []string
+		WorkingDir        string
+		PostStart         *corev1.LifecycleHandler
+		PreStop           *corev1.LifecycleHandler
+		GracePeriod       *int64
+		RunAsUser         *int64
+		RunAsGroup        *int64
+		FsGroup           *int64
+		ReadOnlyRoot      bool
+		Seccomp           *corev1.SeccompProfile
+		Resources         corev1.ResourceRequirements
+		InitContainers    []corev1.Container
+		Sidecars          []corev1.Container
+		Tolerations       []corev1.Toleration
+		PriorityClassName string
+		DNSPolicy         corev1.DNSPolicy
+		DNSConfig         *corev1.PodDNSConfig
+		HostAliases       []corev1.HostAlias
+		ImagePullSecrets  []corev1.LocalObjectReference
+		ImagePullPolicy   corev1.PullPolicy
 	}
 )
 
@@ -45,6 +74,91 @@ type (
 		Port        []PortInfo
 		Volume      []VolumeInfo
 		Restart     string
+		NodeLabel   []LabelInfo // NodeName为空时自动调度, 目标节点需要匹配的标签
+		Security    SecurityInfo
+		Resource    ResourceReqInfo
+		Command     []string // 覆盖镜像的 ENTRYPOINT
+		Args        []string // 覆盖镜像的 CMD
+		WorkingDir  string
+		PostStart   *HookInfo // 容器启动后执行的钩子
+		PreStop     *HookInfo // 容器停止前执行的钩子
+		// TerminationGracePeriod 优雅停止时间, 单位: 秒, 为空时使用k8s默认值30秒
+		TerminationGracePeriod *int64
+		InitContainers         []ContainerSpecInfo // init容器, 按顺序在主容器启动前执行完成
+		Sidecars               []ContainerSpecInfo // 边车容器, 与主容器同时运行
+		Tolerations            []TolerationInfo    // 污点容忍, 例如运行在master节点
+		NodeAffinity           []NodeAffinityInfo  // 基于节点标签的亲和性, NodeName为空时用于自动调度, 指定NodeName时校验必须满足的规则
+		PriorityClassName      string
+		DNS                    *DNSInfo
+		HostAliases            []HostAliasInfo // 容器 /etc/hosts 配置
+		ImagePullSecrets       []string        // 镜像仓库认证的Secret名称, 通过 RegistryCredentialApply 创建
+		ImagePullPolicy        string          // Always, IfNotPresent, Never, 为空时使用k8s默认值
+	}
+	TolerationInfo struct {
+		Key      string
+		Operator string // Equal(默认), Exists
+		Value    string
+		Effect   string // NoSchedule, PreferNoSchedule, NoExecute, 为空表示所有
+		Seconds  *int64 // NoExecute 时容忍的时间, 单位: 秒
+	}
+	// NodeAffinityInfo 节点亲和性规则, Weight 为0表示必须满足, 1-100 表示优先满足的权重
+	NodeAffinityInfo struct {
+		Key      string
+		Operator string // In, NotIn, Exists, DoesNotExist, Gt, Lt
+		Values   []string
+		Weight   int32
+	}
+	DNSInfo struct {
+		Policy      string // ClusterFirst, ClusterFirstWithHostNet, Default, None
+		Nameservers []string
+		Searches    []string
+		Options     []string // 例如: ndots:2, single-request-reopen
+	}
+	HostAliasInfo struct {
+		IP        string
+		Hostnames []string
+	}
+	// ContainerSpecInfo init容器以及边车容器的配置, 与主容器共享网络以及security中的设备
+	ContainerSpecInfo struct {
+		Name       string
+		Image      string
+		Command    []string
+		Args       []string
+		WorkingDir string
+		Env        []EnvInfo
+		Port       []PortInfo
+		Volume     []VolumeInfo
+		Resource   ResourceReqInfo
+	}
+	// HookInfo 生命周期钩子, Command 与 HttpPort 二选一
+	HookInfo struct {
+		Command  []string // 容器内执行的命令
+		HttpPath string   // http GET 请求的路径
+		HttpPort uint16   // http GET 请求的端口
+	}
+	// ResourceReqInfo 资源申请以及限制, 使用k8s的资源格式, 例如 CPU: 500m, 2; 内存: 512Mi, 2Gi
+	ResourceReqInfo struct {
+		CpuRequest string
+		CpuLimit   string
+		MemRequest string
+		MemLimit   string
+	}
+	// SecurityInfo 细粒度的安全配置, 替代全部放开的特权模式(PRIVILEGED)
+	SecurityInfo struct {
+		CapAdd          []string // 添加的Linux能力, 例如: NET_ADMIN
+		CapDrop         []string // 删除的Linux能力, 例如: ALL
+		RunAsUser       *int64
+		RunAsGroup      *int64
+		FsGroup         *int64 // 挂载卷的属组
+		ReadOnlyRootFs  bool   // 只读根文件系统
+		SeccompProfile  string // RuntimeDefault, Unconfined, localhost/<profile>
+		AppArmorProfile string // runtime/default, unconfined, localhost/<profile>
+		Device          []DeviceInfo
+	}
+	// DeviceInfo 设备挂载, 以字符设备的方式挂载主机设备, 设备的访问权限依赖容器运行时的设备cgroup配置
+	DeviceInfo struct {
+		HostPath      string
+		ContainerPath string
 	}
 	LabelInfo struct {
 		Key   string
@@ -67,24 +181,26 @@ type (
 		Name          string
 		Addr          string
 		Status        string // "Healthy" 为正常
-		DockerVersion string
-		Total         int //容器总数
-		Running       int //运行容器数
-		Paused        int //暂停容器数
-		Stopped       int //停止容器数
+		Ready         bool
+		Unschedulable bool // 节点是否已被禁止调度(cordon)
+		Labels        map[string]string
+		Taints        []TaintInfo
+		DockerVersion string       // 容器运行时版本, 例如: docker://20.10.7, containerd://1.6.8
+		Capacity      ResourceInfo // 节点总资源
+		Allocatable   ResourceInfo // 节点可分配资源
+		Total         int          //容器总数
+		Running       int          //运行容器数
+		Paused        int          //暂停容器数(已启动但未运行: 调度中,拉取镜像,异常等)
+		Stopped       int          //停止容器数
+	}
+	TaintInfo struct {
+		Key    string
+		Value  string
+		Effect string
+	}
+	ResourceInfo struct {
+		Cpu    uint64 //单位: 毫核
+		Memory uint64 //单位: MB
+		Pods   uint64 //可运行pod数
 	}
 )
-
-//node, err := clientset.CoreV1().Nodes().Get(context.Background(), nodeName, metav1.GetOptions{})
-//if err != nil {
-//log.Fatalf("failed to get node: %v", err)
-//}
-//
-//// 检查节点的 Conditions 是否存在 Ready 状态
-//isReady := false
-//for _, condition := range node.Status.Conditions {
-//if condition.Type == v1.NodeReady && condition.Status == v1.ConditionTrue {
-//isReady = true
-//break
-//}
-//}
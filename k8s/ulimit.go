@@ -0,0 +1,127 @@
+package k8s
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+/**
+ *    Description: docker 风格的 ULIMIT, MACADDRESS 环境变量转换为k8s的配置
+ *    ULIMIT: 格式与 docker --ulimit 一致: nofile=65535:65535,nproc=4096, k8s没有ulimit的配置,
+ *    通过添加 SYS_RESOURCE 能力并在 postStart 钩子中使用 prlimit 修改容器1号进程的限制, 镜像需要包含 prlimit(util-linux),
+ *    需要通过 ClusterOptions.EnableUlimit 启用
+ *    MACADDRESS: 通过CNI插件支持的pod注解设置, 注解名称由 ClusterOptions.MacAddressAnnotation 配置
+ *    Date: 2026/10/19
+ */
+
+// ulimitNames docker 支持的 ulimit 名称
+var ulimitNames = map[string]bool{
+	"core": true, "cpu": true, "data": true, "fsize": true, "locks": true, "memlock": true, "msgqueue": true, "nice": true,
+	"nofile": true, "nproc": true, "rss": true, "rtprio": true, "rttime": true, "sigpending": true, "stack": true, "as": true,
+}
+
+type ulimitInfo struct {
+	name string
+	soft string
+	hard string
+}
+
+// parseUlimit 解析 ULIMIT 的值, 多个使用逗号或者空格分隔, 未设置 hard 时与 soft 相同, soft 不能大于 hard
+func parseUlimit(value string) ([]ulimitInfo, error) {
+	var ulimits []ulimitInfo
+	for _, item := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ' ' }) {
+		name, limit, ok := strings.Cut(item, "=")
+		if !ok || !ulimitNames[name] {
+			return nil, fmt.Errorf("invalid ulimit %q, expected <name>=<soft>[:<hard>]", item)
+		}
+		soft, hard, ok := strings.Cut(limit, ":")
+		if !ok {
+			hard = soft
+		}
+		limits := []string{soft, hard}
+		for i, v := range limits {
+			if v == "unlimited" || v == "-1" {
+				limits[i] = "unlimited"
+			} else if _, err := strconv.ParseUint(v, 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid ulimit %q, limit must be a number or unlimited", item)
+			}
+		}
+		if ulimitGreater(limits[0], limits[1]) {
+			return nil, fmt.Errorf("invalid ulimit %q, soft limit must not be greater than hard limit", item)
+		}
+		ulimits = append(ulimits, ulimitInfo{name: name, soft: limits[0], hard: limits[1]})
+	}
+	if len(ulimits) == 0 {
+		return nil, fmt.Errorf("ulimit %q is empty", value)
+	}
+	return ulimits, nil
+}
+
+// ulimitGreater 比较两个限制值, unlimited 最大
+func ulimitGreater(a, b string) bool {
+	if a == b || a != "unlimited" && b == "unlimited" {
+		return false
+	}
+	if a == "unlimited" {
+		return true
+	}
+	x, _ := strconv.ParseUint(a, 10, 64)
+	y, _ := strconv.ParseUint(b, 10, 64)
+	return x > y
+}
+
+// ulimitPostStart 生成设置容器1号进程限制的 postStart 命令, 直接执行 prlimit, 不依赖镜像中的shell
+func ulimitPostStart(ulimits []ulimitInfo) []string {
+	args := []string{"prlimit", "--pid", "1"}
+	for _, ulimit := range ulimits {
+		args = append(args, fmt.Sprintf("--%s=%s:%s", ulimit.name, ulimit.soft, ulimit.hard))
+	}
+	return args
+}
+
+// validateUlimitSupport 集群未启用 ULIMIT 时返回错误, 在创建以及更新的校验时执行
+func (manage *ManagerK8s) validateUlimitSupport(info *CreateReqInfo) error {
+	if manage.options.EnableUlimit {
+		return nil
+	}
+	for _, env := range info.Env {
+		if env.Key == ENV_ULIMIT_NAME {
+			return fmt.Errorf("ULIMIT %q is not enabled for this cluster, set ClusterOptions.EnableUlimit after making sure images contain prlimit", env.Value)
+		}
+	}
+	return nil
+}
+
+// applyUlimit 将 ULIMIT 转换为 SYS_RESOURCE 能力以及 postStart 钩子
+func (manage *ManagerK8s) applyUlimit(createInfo *ContainerCreateInfo, value string) error {
+	if !manage.options.EnableUlimit {
+		return fmt.Errorf("ULIMIT %q is not enabled for this cluster (ClusterOptions.EnableUlimit)", value)
+	}
+	ulimits, err := parseUlimit(value)
+	if err != nil {
+		return err
+	}
+	createInfo.CapAdd = append(createInfo.CapAdd, "SYS_RESOURCE")
+	createInfo.UlimitHook = ulimitPostStart(ulimits)
+	return nil
+}
+
+// applyMacAddress 将 MACADDRESS 转换为CNI插件的pod注解
+func (manage *ManagerK8s) applyMacAddress(createInfo *ContainerCreateInfo, value string) error {
+	if _, err := net.ParseMAC(value); err != nil {
+		return fmt.Errorf("invalid MACADDRESS %q: %v", value, err)
+	}
+	if createInfo.HostNetwork {
+		return fmt.Errorf("MACADDRESS %q is not supported with host network", value)
+	}
+	if manage.options.MacAddressAnnotation == "" {
+		return fmt.Errorf("MACADDRESS %q is not supported by this cluster, ClusterOptions.MacAddressAnnotation is not configured", value)
+	}
+	if createInfo.Annotations == nil {
+		createInfo.Annotations = make(map[string]string)
+	}
+	createInfo.Annotations[manage.options.MacAddressAnnotation] = value
+	return nil
+}
@@ -0,0 +1,181 @@
+package k8s
+
+import (
+	"fmt"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"net"
+	"path"
+	"regexp"
+	"strings"
+)
+
+/**
+ *    Description: 创建请求的参数校验, 一次性返回所有字段的错误信息
+ *    Date: 2026/10/19
+ */
+
+// imageReferenceRegexp 镜像地址格式: [域名[:端口]/]路径[:标签][@sha256:摘要]
+var imageReferenceRegexp = regexp.MustCompile(`^(?:[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)*(?::[0-9]+)?/)?` +
+	`[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*` +
+	`(?::[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127})?(?:@sha256:[a-f0-9]{64})?$`)
+
+var supportProtocols = map[string]bool{"TCP": true, "UDP": true, "SCTP": true}
+
+// Validate 校验创建请求, 返回所有字段的错误(utilerrors.Aggregate), 校验通过返回nil
+func (info *CreateReqInfo) Validate() error {
+	var allErrs field.ErrorList
+	// name: 同时作为StatefulSet名称,容器名称以及服务名称, pod名称为: name-0
+	namePath := field.NewPath("Name")
+	if info.Name == "" {
+		allErrs = append(allErrs, field.Required(namePath, ""))
+	} else {
+		for _, msg := range validation.IsDNS1123Label(info.Name + "-0") {
+			allErrs = append(allErrs, field.Invalid(namePath, info.Name, msg))
+		}
+	}
+	// nodeName: 作为节点标签以及选择器标签的值
+	if info.NodeName != "" {
+		nodePath := field.NewPath("NodeName")
+		for _, msg := range validation.IsValidLabelValue(info.NodeName) {
+			allErrs = append(allErrs, field.Invalid(nodePath, info.NodeName, msg))
+		}
+		if info.Name != "" {
+			for _, msg := range validation.IsValidLabelValue(fmt.Sprintf("%s-%s", info.NodeName, info.Name)) {
+				allErrs = append(allErrs, field.Invalid(namePath, info.Name, "selector label \"NodeName-Name\": "+msg))
+			}
+		}
+	}
+	// image
+	imagePath := field.NewPath("Image")
+	if info.Image == "" {
+		allErrs = append(allErrs, field.Required(imagePath, ""))
+	} else if !imageReferenceRegexp.MatchString(info.Image) {
+		allErrs = append(allErrs, field.Invalid(imagePath, info.Image, "invalid image reference, expected [registry[:port]/]repository[:tag][@sha256:digest]"))
+	}
+	allErrs = append(allErrs, validateLabels(info.Label, field.NewPath("Label"), true)...)
+	allErrs = append(allErrs, validateLabels(info.NodeLabel, field.NewPath("NodeLabel"), false)...)
+	allErrs = append(allErrs, validateEnv(info.Env, field.NewPath("Env"))...)
+	allErrs = append(allErrs, validatePorts(info.Port, info.HostNetwork, field.NewPath("Port"))...)
+	allErrs = append(allErrs, validateVolumes(info.Volume, field.NewPath("Volume"))...)
+	allErrs = append(allErrs, validateSecurity(info.Security, field.NewPath("Security"))...)
+	allErrs = append(allErrs, validateResource(info.Resource, field.NewPath("Resource"))...)
+	allErrs = append(allErrs, validateLifecycle(info)...)
+	allErrs = append(allErrs, validateExtraContainers(info)...)
+	allErrs = append(allErrs, validateScheduling(info)...)
+	allErrs = append(allErrs, validateImagePull(info)...)
+	// restart: StatefulSet 仅仅支持 Always, 为空时默认 Always
+	if info.Restart != "" && info.Restart != RESTART_Always {
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("Restart"), info.Restart, []string{RESTART_Always}))
+	}
+	return allErrs.ToAggregate()
+}
+
+func validateLabels(labels []LabelInfo, fldPath *field.Path, reserved bool) field.ErrorList {
+	var allErrs field.ErrorList
+	keys := make(map[string]bool)
+	for i, label := range labels {
+		idxPath := fldPath.Index(i)
+		for _, msg := range validation.IsQualifiedName(label.Key) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("Key"), label.Key, msg))
+		}
+		for _, msg := range validation.IsValidLabelValue(label.Value) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("Value"), label.Value, msg))
+		}
+		if reserved && (label.Key == labelNodeIP || label.Key == labelApp) {
+			allErrs = append(allErrs, field.Forbidden(idxPath.Child("Key"), fmt.Sprintf("label %q is reserved", label.Key)))
+		}
+		if keys[label.Key] {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("Key"), label.Key))
+		}
+		keys[label.Key] = true
+	}
+	return allErrs
+}
+
+func validateEnv(envs []EnvInfo, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	keys := make(map[string]bool)
+	for i, env := range envs {
+		idxPath := fldPath.Index(i).Child("Key")
+		for _, msg := range validation.IsEnvVarName(env.Key) {
+			allErrs = append(allErrs, field.Invalid(idxPath, env.Key, msg))
+		}
+		if keys[env.Key] {
+			allErrs = append(allErrs, field.Duplicate(idxPath, env.Key))
+		}
+		keys[env.Key] = true
+		// docker 风格的自定义属性
+		switch env.Key {
+		case ENV_ULIMIT_NAME:
+			if _, err := parseUlimit(env.Value); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("Value"), env.Value, err.Error()))
+			}
+		case ENV_MACADDRESS:
+			if _, err := net.ParseMAC(env.Value); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("Value"), env.Value, err.Error()))
+			}
+		}
+	}
+	return allErrs
+}
+
+func validatePorts(ports []PortInfo, hostNetwork bool, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	innerPorts := make(map[string]bool)
+	outerPorts := make(map[string]bool)
+	for i, port := range ports {
+		idxPath := fldPath.Index(i)
+		if !supportProtocols[port.Protocol] {
+			allErrs = append(allErrs, field.NotSupported(idxPath.Child("Protocol"), port.Protocol, []string{"TCP", "UDP", "SCTP"}))
+		}
+		for _, msg := range validation.IsValidPortNum(int(port.InnerPort)) {
+			allErrs = append(allErrs, field.Invalid(idxPath.Child("InnerPort"), int(port.InnerPort), msg))
+		}
+		innerKey := fmt.Sprintf("%d/%s", port.InnerPort, port.Protocol)
+		if innerPorts[innerKey] {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("InnerPort"), innerKey))
+		}
+		innerPorts[innerKey] = true
+		// host模式下不使用端口映射
+		if hostNetwork || port.OuterPort == 0 {
+			continue
+		}
+		outerKey := fmt.Sprintf("%d/%s", port.OuterPort, port.Protocol)
+		if outerPorts[outerKey] {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("OuterPort"), outerKey))
+		}
+		outerPorts[outerKey] = true
+	}
+	return allErrs
+}
+
+func validateVolumes(volumes []VolumeInfo, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	mountPaths := make(map[string]bool)
+	for i, volume := range volumes {
+		idxPath := fldPath.Index(i)
+		allErrs = append(allErrs, validateAbsPath(volume.InnerPath, idxPath.Child("InnerPath"))...)
+		allErrs = append(allErrs, validateAbsPath(volume.OuterPath, idxPath.Child("OuterPath"))...)
+		if mountPaths[path.Clean(volume.InnerPath)] {
+			allErrs = append(allErrs, field.Duplicate(idxPath.Child("InnerPath"), volume.InnerPath))
+		}
+		mountPaths[path.Clean(volume.InnerPath)] = true
+	}
+	return allErrs
+}
+
+func validateAbsPath(p string, fldPath *field.Path) field.ErrorList {
+	if p == "" {
+		return field.ErrorList{field.Required(fldPath, "")}
+	}
+	if !path.IsAbs(p) {
+		return field.ErrorList{field.Invalid(fldPath, p, "must be an absolute path")}
+	}
+	for _, item := range strings.Split(p, "/") {
+		if item == ".." {
+			return field.ErrorList{field.Invalid(fldPath, p, "must not contain '..'")}
+		}
+	}
+	return nil
+}
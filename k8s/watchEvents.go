@@ -2,11 +2,11 @@ package k8s
 
 import (
 	"context"
+	"fmt"
 	logger "github.com/alecthomas/log4go"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/watch"
-	"strings"
 	"time"
 )
 
@@ -15,6 +15,28 @@ import (
  *    Date: 2024/11/28
  */
 
+// expectedDeleteTTL 主动删除的标记有效期, 超过后的删除事件按异常删除通知
+const expectedDeleteTTL = 10 * time.Minute
+
+// expectDeletion 标记app的pod即将被组件主动删除
+func (api *k8sApi) expectDeletion(name, namespace string) {
+	api.expectedDeletes.Store(name+"_"+namespace, time.Now())
+}
+
+// cancelDeletion 删除请求失败时取消标记, 返回原错误
+func (api *k8sApi) cancelDeletion(name, namespace string, err error) error {
+	if err != nil {
+		api.expectedDeletes.Delete(name + "_" + namespace)
+	}
+	return err
+}
+
+// deletionExpected pod删除是否为组件主动删除, 标记只使用一次
+func (api *k8sApi) deletionExpected(name, namespace string) bool {
+	value, ok := api.expectedDeletes.LoadAndDelete(name + "_" + namespace)
+	return ok && time.Since(value.(time.Time)) < expectedDeleteTTL
+}
+
 func (api *k8sApi) watchPodEvents(namespace string) {
 	init := true
 	// TODO: 待处理 k8s状态监控相关的缓存处理,所有事件执行后都会异步触发到监控模块进行缓存创建实时更新
@@ -22,6 +44,7 @@ func (api *k8sApi) watchPodEvents(namespace string) {
 		var restart bool
 		watchChan, err := api.client.CoreV1().Pods(namespace).Watch(context.TODO(), metav1.ListOptions{Watch: true})
 		if err != nil {
+			internalMetrics.apiError("pod_watch")
 			err = logger.Error("域名: %s, 创建监控出现异常: :%v ", namespace, err)
 			if init {
 				panic(err)
@@ -32,6 +55,7 @@ func (api *k8sApi) watchPodEvents(namespace string) {
 		for {
 			if restart {
 				logger.Error("域名: %s, 监听事件出现异常: %v,等待重新创建监听器", namespace)
+				internalMetrics.watchReconnect("pods", namespace)
 				watchChan.Stop()
 				break
 			}
@@ -47,12 +71,11 @@ func (api *k8sApi) watchPodEvents(namespace string) {
 				if !ok {
 					continue
 				}
-				var podName string
-				if namespace == api.systemNamespace {
-					podName = pod.Name
-				} else {
-					podName = strings.TrimRight(pod.Name, "-0")
+				// 预拉取的pod只记录名称, 不缓存也不通知
+				if api.trackPrePullPod(pod, event.Type == watch.Deleted) {
+					continue
 				}
+				podName := api.podAppName(pod.Name, namespace)
 				switch event.Type {
 				case watch.Added:
 					logger.Info("添加事件: 容器: %s,所在域名空间: %s,最新状态: %v", podName, pod.Namespace, pod.Status.Phase)
@@ -70,17 +93,19 @@ func (api *k8sApi) watchPodEvents(namespace string) {
 				if event.Type == watch.Deleted {
 					logger.Warn("容器: %s,所在域名空间: %s, 删除事件, 删除缓存", podName, pod.Namespace)
 					DefaultK8SMgr.DelCacheContainerMonitor(podName, namespace)
+					// 组件主动删除(删除, 停止, 重启, drain迁移)的pod不发送通知
+					if api.deletionExpected(podName, namespace) {
+						logger.Info("容器: %s,所在域名空间: %s, 组件主动删除, 不发送删除通知", podName, pod.Namespace)
+						continue
+					}
+					DefaultK8SMgr.NotifyEvent(Notification{
+						Status: EventDeleted, Severity: SeverityInfo, App: podName, Namespace: namespace, Node: pod.Status.HostIP, Labels: pod.Labels,
+						Message: fmt.Sprintf("【容器: %s】pod已删除, 最新状态: %v", podName, pod.Status.Phase),
+					})
 				} else {
 					// 信息变更缓存更新
-					if len(pod.Status.ContainerStatuses) > 0 && pod.Status.ContainerStatuses[0].State.Running != nil {
-						DefaultK8SMgr.SetCacheContainerInfo(podName, namespace, ContainerInfo{
-							Name:         podName,
-							HostIP:       pod.Status.HostIP,
-							PodIP:        pod.Status.PodIP,
-							Status:       string(pod.Status.Phase),
-							ReStartCount: int(pod.Status.ContainerStatuses[0].RestartCount),
-							NewStartAt:   pod.Status.ContainerStatuses[0].State.Running.StartedAt.Time,
-						})
+					if len(pod.Status.ContainerStatuses) > 0 {
+						DefaultK8SMgr.SetCacheContainerInfo(podName, namespace, podToContainerInfo(podName, pod))
 					}
 				}
 			}
@@ -88,3 +113,51 @@ func (api *k8sApi) watchPodEvents(namespace string) {
 		time.Sleep(1 * time.Minute)
 	}
 }
+
+// watchNodeEvents 节点状态监听, 实时更新节点缓存
+func (api *k8sApi) watchNodeEvents() {
+	for {
+		watchChan, err := api.client.CoreV1().Nodes().Watch(context.TODO(), metav1.ListOptions{Watch: true})
+		if err != nil {
+			internalMetrics.apiError("node_watch")
+			logger.Error("节点监听器创建出现异常: %v, 等待重新创建监听器", err)
+		} else {
+			if exit := api.consumeNodeEvents(watchChan); exit {
+				return
+			}
+			internalMetrics.watchReconnect("nodes", "")
+		}
+		select {
+		case <-api.exitCh:
+			return
+		case <-time.After(1 * time.Minute):
+		}
+	}
+}
+
+// consumeNodeEvents 消费节点事件, 返回值表示是否退出监听
+func (api *k8sApi) consumeNodeEvents(watchChan watch.Interface) bool {
+	defer watchChan.Stop()
+	for {
+		select {
+		case <-api.exitCh:
+			return true
+		case event, ok := <-watchChan.ResultChan():
+			if !ok || event.Type == watch.Error {
+				logger.Error("节点监听事件出现异常, 等待重新创建监听器")
+				return false
+			}
+			node, ok := event.Object.(*corev1.Node)
+			if !ok {
+				continue
+			}
+			switch event.Type {
+			case watch.Added, watch.Modified:
+				DefaultK8SMgr.SetCacheNodeInfo(nodeToInfo(node))
+			case watch.Deleted:
+				logger.Warn("删除事件: 节点: %s", node.Name)
+				DefaultK8SMgr.DelCacheNodeInfo(node.Name)
+			}
+		}
+	}
+}
@@ -0,0 +1,265 @@
+package k8s
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	logger "github.com/alecthomas/log4go"
+	"net/http"
+	"sync"
+	"text/template"
+	"time"
+)
+
+/**
+ *    Description: webhook通知器: 将告警以及生命周期事件以JSON格式POST到指定地址, 支持模板, HMAC签名, 失败重试以及有界队列
+ *    Date: 2026/10/19
+ */
+
+const (
+	WebhookSignatureHeader = "X-Signature-256" // 签名格式: sha256=<hex(HMAC-SHA256(secret, body))>
+
+	defaultWebhookQueueSize  = 100
+	defaultWebhookMaxRetries = 3
+	defaultWebhookBackoff    = time.Second
+	defaultWebhookTimeout    = 5 * time.Second
+)
+
+var ErrWebhookQueueFull = errors.New("webhook queue is full, notification dropped")
+
+type (
+	// WebhookOptions webhook通知器配置, 为0的配置使用默认值
+	WebhookOptions struct {
+		URL        string
+		Headers    map[string]string
+		Template   string        // text/template 模板, 数据为 Notification, 渲染结果必须为JSON; 为空时直接发送 Notification 的JSON
+		Secret     string        // 不为空时对请求体进行 HMAC-SHA256 签名
+		Types      []string      // 发送的通知类型: alert, event, 为空表示全部
+		QueueSize  int           // 默认: 100, 队列满时丢弃新的通知
+		MaxRetries *int          // 为空时默认: 3, 0 表示不重试; 网络异常, 429 以及 5xx 时重试
+		Backoff    time.Duration // 默认: 1s, 每次重试翻倍
+		Timeout    time.Duration // 默认: 5s, 单次请求超时时间
+	}
+	// WebhookNotifier webhook通知器, 通知在后台按顺序发送
+	WebhookNotifier struct {
+		name       string
+		opt        WebhookOptions
+		maxRetries int
+		tmpl       *template.Template
+		client     *http.Client
+		queue      chan Notification
+		exitCh     chan bool
+		wg         sync.WaitGroup
+		once       sync.Once
+		lock       sync.Mutex // 保护统计以及 closed, 入队与关闭使用同一把锁, 关闭后不会再有通知入队
+		closed     bool
+		sent       int
+		failed     int
+		dropped    int
+		lastErr    error
+	}
+	// WebhookStats webhook通知器的发送统计
+	WebhookStats struct {
+		Sent    int
+		Failed  int
+		Dropped int
+		Queued  int
+		LastErr error
+	}
+)
+
+// webhookFuncs 模板函数, json 用于输出转义后的JSON值, 例如: {"text": {{json .Message}}}
+var webhookFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// NewWebhookNotifier 创建webhook通知器并启动后台发送, 不再使用时需要调用 Close
+func NewWebhookNotifier(name string, opt WebhookOptions) (*WebhookNotifier, error) {
+	if opt.URL == "" {
+		return nil, errors.New("webhook url is required")
+	}
+	if opt.QueueSize <= 0 {
+		opt.QueueSize = defaultWebhookQueueSize
+	}
+	maxRetries := defaultWebhookMaxRetries
+	if opt.MaxRetries != nil {
+		if *opt.MaxRetries < 0 {
+			return nil, errors.New("webhook maxRetries must not be negative")
+		}
+		maxRetries = *opt.MaxRetries
+	}
+	if opt.Backoff <= 0 {
+		opt.Backoff = defaultWebhookBackoff
+	}
+	if opt.Timeout <= 0 {
+		opt.Timeout = defaultWebhookTimeout
+	}
+	notifier := &WebhookNotifier{
+		name:       name,
+		opt:        opt,
+		maxRetries: maxRetries,
+		client:     &http.Client{Timeout: opt.Timeout},
+		queue:      make(chan Notification, opt.QueueSize),
+		exitCh:     make(chan bool),
+	}
+	if opt.Template != "" {
+		tmpl, err := template.New(name).Funcs(webhookFuncs).Option("missingkey=error").Parse(opt.Template)
+		if err != nil {
+			return nil, fmt.Errorf("parse webhook template failed: %v", err)
+		}
+		notifier.tmpl = tmpl
+	}
+	notifier.wg.Add(1)
+	go notifier.run()
+	return notifier, nil
+}
+
+func (notifier *WebhookNotifier) Name() string {
+	return notifier.name
+}
+
+// Notify 通知加入发送队列, 不阻塞; 队列已满或者已关闭时返回错误
+func (notifier *WebhookNotifier) Notify(notification Notification) error {
+	if len(notifier.opt.Types) > 0 && !containsString(notifier.opt.Types, notification.Type) {
+		return nil
+	}
+	notifier.lock.Lock()
+	defer notifier.lock.Unlock()
+	if notifier.closed {
+		return errors.New("webhook notifier is closed")
+	}
+	select {
+	case notifier.queue <- notification:
+		return nil
+	default:
+		notifier.dropped++
+		return ErrWebhookQueueFull
+	}
+}
+
+// Close 停止接收通知, 等待队列中的通知发送完成(不再重试)
+func (notifier *WebhookNotifier) Close() {
+	notifier.once.Do(func() {
+		notifier.lock.Lock()
+		notifier.closed = true
+		notifier.lock.Unlock()
+		close(notifier.exitCh)
+	})
+	notifier.wg.Wait()
+}
+
+// Stats 获取发送统计
+func (notifier *WebhookNotifier) Stats() WebhookStats {
+	notifier.lock.Lock()
+	defer notifier.lock.Unlock()
+	return WebhookStats{Sent: notifier.sent, Failed: notifier.failed, Dropped: notifier.dropped, Queued: len(notifier.queue), LastErr: notifier.lastErr}
+}
+
+func (notifier *WebhookNotifier) run() {
+	defer notifier.wg.Done()
+	for {
+		select {
+		case notification := <-notifier.queue:
+			notifier.deliver(notification)
+		case <-notifier.exitCh:
+			for {
+				select {
+				case notification := <-notifier.queue:
+					notifier.deliver(notification)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver 发送通知并记录发送统计
+func (notifier *WebhookNotifier) deliver(notification Notification) {
+	body, err := notifier.render(notification)
+	if err == nil {
+		err = notifier.send(body)
+	}
+	notifier.lock.Lock()
+	defer notifier.lock.Unlock()
+	if err != nil {
+		notifier.failed++
+		notifier.lastErr = err
+		logger.Error("webhook[%s]发送通知失败: %v, 通知: %s", notifier.name, err, notification.Message)
+		return
+	}
+	notifier.sent++
+}
+
+// send 发送请求, 失败时按退避时间重试
+func (notifier *WebhookNotifier) send(body []byte) error {
+	backoff := notifier.opt.Backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := notifier.post(body)
+		if err == nil || !retry || attempt > notifier.maxRetries {
+			return err
+		}
+		logger.Warn("webhook[%s]发送通知失败: %v, %v 后第 %d 次重试", notifier.name, err, backoff, attempt)
+		select {
+		case <-time.After(backoff):
+			backoff *= 2
+		case <-notifier.exitCh:
+			// 关闭时不再重试, 以免阻塞退出
+			return err
+		}
+	}
+}
+
+// render 生成请求体
+func (notifier *WebhookNotifier) render(notification Notification) ([]byte, error) {
+	if notifier.tmpl == nil {
+		return json.Marshal(notification)
+	}
+	var buf bytes.Buffer
+	if err := notifier.tmpl.Execute(&buf, notification); err != nil {
+		return nil, fmt.Errorf("render webhook template failed: %v", err)
+	}
+	if !json.Valid(buf.Bytes()) {
+		return nil, fmt.Errorf("webhook template output is not valid json: %s", buf.String())
+	}
+	return buf.Bytes(), nil
+}
+
+// post 发送请求, 返回值表示失败时是否可以重试
+func (notifier *WebhookNotifier) post(body []byte) (bool, error) {
+	req, err := http.NewRequest(http.MethodPost, notifier.opt.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range notifier.opt.Headers {
+		req.Header.Set(key, value)
+	}
+	if notifier.opt.Secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhookBody(notifier.opt.Secret, body))
+	}
+	resp, err := notifier.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook response status: %s", resp.Status)
+}
+
+// SignWebhookBody 计算请求体的签名, 接收方可以用于校验 X-Signature-256
+func SignWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}